@@ -0,0 +1,123 @@
+// This file contains the node tree produced by parsing Frala syntax
+
+package frala
+
+import (
+	"strconv"
+)
+
+// NodeType is the type of a Node in a Document
+type NodeType int
+
+const (
+	// TextNode is content outside of Frala syntax, passed through as-is
+	TextNode NodeType = iota
+
+	// TagNode is Frala syntax, such as {{ type="term" src="hello" }}
+	TagNode
+)
+
+// Position is a location in the source of a Document
+type Position struct {
+	Offset int // Byte offset, starting at 0
+	Line   int // Line number, starting at 1
+	Column int // Column number in runes, starting at 1
+}
+
+// String returns the Position as line:column
+func (p Position) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// Attribute is a single name=value pair of a TagNode
+type Attribute struct {
+	Name   string   // Name of the attribute, such as src
+	Value  string   // Value of the attribute, with any quotes and escapes removed
+	Quoted bool     // Quoted is whether the value was declared in quotes
+	Pos    Position // Pos is the position of the attribute name
+}
+
+// Node is a single TextNode or TagNode of a Document
+type Node struct {
+	Type       NodeType    // Type of the Node
+	Text       string      // Text is the content of a TextNode, or the raw source of a TagNode
+	Attributes []Attribute // Attributes of a TagNode, in the order they were declared
+	Pos        Position    // Pos is where the Node starts in the source
+	End        Position    // End is where the Node ends in the source
+}
+
+// Attribute gets the value of the named attribute of a Node, if it exists
+func (n *Node) Attribute(name string) (string, bool) {
+	for _, attribute := range n.Attributes { // For each attribute of the Node
+		if attribute.Name == name {
+			return attribute.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Document is the node tree of a parsed file
+type Document struct {
+	Name     string       // Name of the file the Document was parsed from
+	Nodes    []Node       // Nodes of the Document, in source order
+	Warnings []ParseError // Warnings are problems which did not stop the Document being parsed, such as an unterminated {{ kept as text
+}
+
+// ParseDocument tokenizes and parses content into a Document, returning a ParseError of ErrorSyntax if the Frala syntax is malformed
+// Frala syntax which is never closed, such as a stray {{ at the end of the content, is kept as text and reported as one of the Warnings of the Document.
+func ParseDocument(name, content string) (*Document, error) {
+	l := newLexer(name, content)
+	tokens, lexErr := l.run()
+
+	if lexErr != nil { // If we failed to tokenize the content
+		return nil, lexErr
+	}
+
+	document := &Document{Name: name, Warnings: l.warnings}
+
+	for index := 0; tokens[index].typ != tokenEOF; index++ { // For each token until the end of the content
+		tok := tokens[index]
+
+		if tok.typ == tokenText { // Content outside of Frala syntax
+			document.Nodes = append(document.Nodes, Node{Type: TextNode, Text: tok.val, Pos: l.position(tok.offset), End: l.position(tok.end)})
+			continue
+		}
+
+		node := Node{Type: TagNode, Pos: l.position(tok.offset)} // tok is a tokenOpen, since the lexer always follows it with a tag
+		index++
+
+		for tokens[index].typ != tokenClose { // For each attribute until the end of the Frala syntax
+			nameTok := tokens[index]
+
+			if nameTok.typ != tokenWord {
+				return nil, l.errorf(nameTok.offset, "Expected attribute name")
+			}
+
+			if tokens[index+1].typ != tokenEquals {
+				return nil, l.errorf(tokens[index+1].offset, "Expected = after attribute "+nameTok.val)
+			}
+
+			valueTok := tokens[index+2]
+
+			if valueTok.typ != tokenString && valueTok.typ != tokenWord {
+				return nil, l.errorf(valueTok.offset, "Expected value for attribute "+nameTok.val)
+			}
+
+			node.Attributes = append(node.Attributes, Attribute{
+				Name:   nameTok.val,
+				Value:  valueTok.val,
+				Quoted: valueTok.typ == tokenString,
+				Pos:    l.position(nameTok.offset),
+			})
+
+			index += 3
+		}
+
+		node.End = l.position(tokens[index].end)
+		node.Text = content[node.Pos.Offset:node.End.Offset]
+		document.Nodes = append(document.Nodes, node)
+	}
+
+	return document, nil
+}
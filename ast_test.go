@@ -0,0 +1,169 @@
+// This file contains the tests of tokenizing and parsing Frala syntax
+
+package frala
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseDocument ensures Frala syntax is parsed into Nodes with their attributes and positions
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		nodes      []string            // Text of each Node, with Frala syntax prefixed by tag:
+		attributes []map[string]string // Attributes of each TagNode, in order
+		warning    string              // Position of a warning, if any
+	}{
+		{
+			name:    "text only",
+			content: "<p>Hello</p>",
+			nodes:   []string{"<p>Hello</p>"},
+		},
+		{
+			name:       "term",
+			content:    `<p>{{ type="term" src="hello" }}</p>`,
+			nodes:      []string{"<p>", `tag:{{ type="term" src="hello" }}`, "</p>"},
+			attributes: []map[string]string{{"type": "term", "src": "hello"}},
+		},
+		{
+			name:       "quotes and escapes",
+			content:    `{{ type='term' src="say \"hi\"" name='it\'s' path="a\\b" }}`,
+			nodes:      []string{`tag:{{ type='term' src="say \"hi\"" name='it\'s' path="a\\b" }}`},
+			attributes: []map[string]string{{"type": "term", "src": `say "hi"`, "name": "it's", "path": `a\b`}},
+		},
+		{
+			name:       "unquoted and multi-line",
+			content:    "{{\n\ttype=term\n\tsrc=hello}}",
+			nodes:      []string{"tag:{{\n\ttype=term\n\tsrc=hello}}"},
+			attributes: []map[string]string{{"type": "term", "src": "hello"}},
+		},
+		{
+			name:    "unterminated",
+			content: "<p>{{ type=\"term\" src=\"hello\"",
+			nodes:   []string{"<p>{{ type=\"term\" src=\"hello\""},
+			warning: "1:4",
+		},
+		{
+			name:    "lone open",
+			content: "a\n{{ b",
+			nodes:   []string{"a\n{{ b"},
+			warning: "2:1",
+		},
+		{
+			name:       "unterminated after a term",
+			content:    `{{ type="term" src="a" }} and {{`,
+			nodes:      []string{`tag:{{ type="term" src="a" }}`, " and {{"},
+			attributes: []map[string]string{{"type": "term", "src": "a"}},
+			warning:    "1:31",
+		},
+		{
+			name:    "unterminated quote",
+			content: "{{ type=\"term src=hello }}",
+			nodes:   []string{"{{ type=\"term src=hello }}"},
+			warning: "1:9",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, parseErr := ParseDocument("test.html", test.content)
+
+			if parseErr != nil {
+				t.Fatal(parseErr)
+			}
+
+			var nodes []string
+			var attributes []map[string]string
+
+			for _, node := range document.Nodes {
+				if node.Type == TextNode {
+					nodes = append(nodes, node.Text)
+					continue
+				}
+
+				nodes = append(nodes, "tag:"+node.Text)
+				nodeAttributes := make(map[string]string)
+
+				for _, attribute := range node.Attributes {
+					nodeAttributes[attribute.Name] = attribute.Value
+				}
+
+				attributes = append(attributes, nodeAttributes)
+			}
+
+			if strings.Join(nodes, "|") != strings.Join(test.nodes, "|") {
+				t.Errorf("Nodes = %q, want %q", nodes, test.nodes)
+			}
+
+			if len(attributes) != len(test.attributes) {
+				t.Fatalf("Attributes = %v, want %v", attributes, test.attributes)
+			}
+
+			for index, want := range test.attributes {
+				for name, value := range want {
+					if attributes[index][name] != value {
+						t.Errorf("attribute %s = %q, want %q", name, attributes[index][name], value)
+					}
+				}
+			}
+
+			if test.warning == "" && len(document.Warnings) != 0 {
+				t.Errorf("Warnings = %v, want none", document.Warnings)
+			} else if test.warning != "" && (len(document.Warnings) != 1 || Position{Line: document.Warnings[0].Line, Column: document.Warnings[0].Column}.String() != test.warning) {
+				t.Errorf("Warnings = %v, want one at %s", document.Warnings, test.warning)
+			}
+		})
+	}
+}
+
+// TestParseDocumentMalformed ensures malformed Frala syntax is reported as an ErrorSyntax at its position
+func TestParseDocumentMalformed(t *testing.T) {
+	tests := []struct {
+		content  string
+		position string
+		message  string
+	}{
+		{`{{ type="term" {{ src="a" }}`, "1:16", "Unexpected {{"},
+		{`{{ type }}`, "1:9", "Expected = after attribute type"},
+		{`{{ type= }}`, "1:10", "Expected value for attribute type"},
+		{`{{ ="term" }}`, "1:4", "Expected attribute name"},
+		{"<p>\n  {{ src=\"a\" \"b\" }}", "2:14", "Expected attribute name"},
+	}
+
+	for _, test := range tests {
+		_, parseErr := ParseDocument("test.html", test.content)
+		syntaxErr, isParseErr := parseErr.(*ParseError)
+
+		if !isParseErr {
+			t.Errorf("ParseDocument(%q) error = %v, want a ParseError", test.content, parseErr)
+			continue
+		}
+
+		if position := (Position{Line: syntaxErr.Line, Column: syntaxErr.Column}).String(); syntaxErr.Kind != ErrorSyntax || position != test.position || !strings.Contains(syntaxErr.Message, test.message) {
+			t.Errorf("ParseDocument(%q) = %s %v, want %s at %s", test.content, syntaxErr.Kind, syntaxErr, test.message, test.position)
+		}
+	}
+}
+
+// TestUnterminatedStrict ensures unterminated Frala syntax is kept as text with a Diagnostic, and only fails the parse when Strict
+func TestUnterminatedStrict(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hello"}}}`, nil)
+	source := `{{ type="term" src="hello" }} {{ oops`
+
+	for _, strict := range []bool{false, true} {
+		var content strings.Builder
+		diagnostics, renderErr := engine.RenderReader(&content, "page.html", strings.NewReader(source), RenderOptions{Strict: strict})
+
+		if len(diagnostics) != 1 || diagnostics[0].Kind != ErrorSyntax {
+			t.Errorf("Strict %v: Diagnostics = %v, want one syntax Diagnostic", strict, diagnostics)
+		}
+
+		if strict && (renderErr == nil || content.Len() != 0) {
+			t.Errorf("Strict RenderReader = %q, %v, want an error and no content", content.String(), renderErr)
+		} else if !strict && (renderErr != nil || content.String() != "Hello {{ oops") {
+			t.Errorf("RenderReader = %q, %v, want the text without an error", content.String(), renderErr)
+		}
+	}
+}
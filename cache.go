@@ -0,0 +1,159 @@
+// This file contains functionality for caching parsed templates and Fragments
+
+package frala
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// CacheStats are the statistics of a Cache
+type CacheStats struct {
+	Hits    int // Hits is the number of Documents returned from the Cache
+	Misses  int // Misses is the number of Documents read and parsed because they were not cached or had changed
+	Entries int // Entries is the number of Documents currently cached
+}
+
+// Cache is an in-memory cache of parsed Documents, shared across languages and files
+// Entries are keyed by path and validated against the modification time and size of the file, so changed files are parsed again.
+// A Cache is safe for concurrent use and may be shared between Engines, including Engines reading from different FS, whose entries are kept apart.
+type Cache struct {
+	lock    sync.Mutex              // Lock guarding the entries and statistics
+	entries map[cacheKey]cacheEntry // Entries keyed by the absolute path of the file, or its path in an FS
+	hits    int                     // Number of hits
+	misses  int                     // Number of misses
+}
+
+// cacheKey identifies a file cached by any Engine sharing the Cache
+// Paths in an FS are only unique within that FS, and files of an embed.FS all have the same modification time, so the FS is part of the key.
+type cacheKey struct {
+	source interface{} // source is the FS the file is read from, nil for the OS filesystem, or the Engine if its FS can't be compared
+	path   string      // path is the absolute path of the file, or its path in the FS
+}
+
+// cacheEntry is a parsed Document and the state of the file it was parsed from
+type cacheEntry struct {
+	modTime  time.Time // Modification time of the file when it was parsed
+	size     int64     // Size of the file when it was parsed
+	document *Document // Document parsed from the file
+	parseErr error     // Syntax error of the file, if any, so malformed files are not parsed repeatedly
+}
+
+// NewCache creates an empty Cache
+func NewCache() *Cache {
+	return &Cache{entries: make(map[cacheKey]cacheEntry)}
+}
+
+// get gets the Document of a file if it is cached and the file has not changed since
+func (c *Cache) get(key cacheKey, modTime time.Time, size int64) (cacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, exists := c.entries[key]
+
+	if exists && entry.modTime.Equal(modTime) && entry.size == size { // If the file has not changed since it was parsed
+		c.hits++
+		return entry, true
+	}
+
+	c.misses++
+	return cacheEntry{}, false
+}
+
+// set caches the Document of a file
+func (c *Cache) set(key cacheKey, entry cacheEntry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[key] = entry
+}
+
+// Invalidate removes the Document of a path from the Cache, for every FS it is cached for
+// The path is the absolute path of the file, or its path in an FS. See Engine.Invalidate to use any path.
+func (c *Cache) Invalidate(path string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.entries {
+		if key.path == path {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidate removes the Document of a file from the Cache
+func (c *Cache) invalidate(key cacheKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.entries, key)
+}
+
+// InvalidateAll removes every Document from the Cache
+func (c *Cache) InvalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries = make(map[cacheKey]cacheEntry)
+}
+
+// Stats gets the statistics of the Cache
+func (c *Cache) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: len(c.entries)}
+}
+
+// Cache gets the Cache of parsed Documents used by the Engine
+func (e *Engine) Cache() *Cache {
+	return e.cache
+}
+
+// Invalidate removes the Document of a file from the Cache of the Engine, such as after it has been edited
+func (e *Engine) Invalidate(file string) {
+	if cachePath, pathErr := e.includePath(file); pathErr == nil {
+		e.cache.invalidate(e.cacheKey(cachePath))
+	}
+}
+
+// cacheKey gets the key of a file in the Cache, from its absolute path or its path in the FS of the Engine
+func (e *Engine) cacheKey(cachePath string) cacheKey {
+	if e.fsys == nil { // Absolute paths are the same file for every Engine
+		return cacheKey{path: cachePath}
+	}
+
+	if reflect.ValueOf(e.fsys).Comparable() { // If Engines with the same FS can share its entries, such as an embed.FS. The value is checked, since an interface field may hold a map
+		return cacheKey{source: e.fsys, path: cachePath}
+	}
+
+	return cacheKey{source: e, path: cachePath} // Such as an fstest.MapFS, or a struct wrapping one, which can't be a map key
+}
+
+// loadDocument gets the Document of a file from the Cache, reading and parsing it if it isn't cached or has changed
+func (e *Engine) loadDocument(file string) (*Document, error) {
+	cachePath, pathErr := e.includePath(file)
+	fileInfo, statErr := e.statFile(file)
+
+	if pathErr != nil || statErr != nil { // If the file can't be identified, it can't be read either
+		return nil, readError(file)
+	}
+
+	key := e.cacheKey(cachePath)
+
+	if entry, cached := e.cache.get(key, fileInfo.ModTime(), fileInfo.Size()); cached { // If the file has not changed since it was parsed
+		return entry.document, entry.parseErr
+	}
+
+	contentBytes, readErr := e.readFile(file) // Read the file, from the FS of the Engine if it has one
+
+	if len(contentBytes) == 0 || readErr != nil {
+		return nil, readError(file)
+	}
+
+	document, parseErr := ParseDocument(file, string(contentBytes)) // Tokenize and parse the content into a Document
+	e.cache.set(key, cacheEntry{modTime: fileInfo.ModTime(), size: fileInfo.Size(), document: document, parseErr: parseErr})
+
+	return document, parseErr
+}
@@ -0,0 +1,105 @@
+// This file contains the tests of the Cache of parsed Documents
+
+package frala
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// TestCacheStats ensures Documents are reused across languages and parses, and parsed again once edited or invalidated
+func TestCacheStats(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"]}`, map[string]string{
+		"page.html":   `{{ type="fragment" src="footer.html" }}`,
+		"footer.html": "footer",
+	})
+
+	page := filepath.Join(dir, "page.html")
+	engine.MultilingualParse([]string{page}) // Reads both files, then reuses them for the second language
+
+	if stats := engine.Cache().Stats(); stats.Misses != 2 || stats.Hits != 2 || stats.Entries != 2 {
+		t.Errorf("Stats = %+v, want 2 misses, 2 hits and 2 entries", stats)
+	}
+
+	writeTestFiles(t, dir, map[string]string{"footer.html": "edited footer"}) // A different size, so it is parsed again
+
+	if response := engine.Parse(page); response.Content != "edited footer" {
+		t.Errorf("Parse after editing = %q, want edited footer", response.Content)
+	}
+
+	engine.Invalidate(page)
+
+	if stats := engine.Cache().Stats(); stats.Entries != 1 {
+		t.Errorf("Entries after Invalidate = %d, want 1", stats.Entries)
+	}
+
+	engine.Cache().InvalidateAll()
+
+	if stats := engine.Cache().Stats(); stats.Entries != 0 {
+		t.Errorf("Entries after InvalidateAll = %d, want 0", stats.Entries)
+	}
+}
+
+// wrappedFS is an FS wrapping another, which is a comparable type even when the FS it holds can't be compared
+type wrappedFS struct {
+	fs.FS
+}
+
+// TestCacheSharedBetweenFS ensures Engines sharing a Cache never get the Documents of another FS, even with the same paths, sizes and modification times
+func TestCacheSharedBetweenFS(t *testing.T) {
+	first := fstest.MapFS{"page.html": {Data: []byte("<p>First</p>")}}
+	second := fstest.MapFS{"page.html": {Data: []byte("<p>Other</p>")}}
+
+	tests := []struct {
+		name        string
+		first       fs.FS
+		second      fs.FS
+		distinct    bool
+		wantEntries int
+	}{
+		{"comparable FS", &first, &second, true, 2}, // Pointers can be compared, as with an embed.FS
+		{"uncomparable FS", first, second, true, 2},
+		{"same FS", &first, &first, false, 1},
+		{"wrapped uncomparable FS", wrappedFS{first}, wrappedFS{second}, true, 2}, // The type is comparable, but not the MapFS it holds
+		{"wrapped same FS", wrappedFS{&first}, wrappedFS{&first}, false, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := NewCache()
+			var contents []string
+
+			for _, fsys := range []fs.FS{test.first, test.second} { // Parse the same path with an Engine for each FS
+				engine, newErr := New(Options{Config: &ConfigOptions{DefaultLanguage: "en"}, FS: fsys, Cache: cache})
+
+				if newErr != nil {
+					t.Fatal(newErr)
+				}
+
+				response := engine.Parse("page.html")
+
+				if response.Error != nil {
+					t.Fatal(response.Error)
+				}
+
+				contents = append(contents, response.Content)
+			}
+
+			if test.distinct && contents[0] == contents[1] {
+				t.Errorf("both Engines rendered %q", contents[0])
+			}
+
+			if entries := cache.Stats().Entries; entries != test.wantEntries {
+				t.Errorf("Entries = %d, want %d", entries, test.wantEntries)
+			}
+
+			cache.Invalidate("page.html") // Invalidating a path removes it for every FS
+
+			if entries := cache.Stats().Entries; entries != 0 {
+				t.Errorf("Entries after Invalidate = %d, want 0", entries)
+			}
+		})
+	}
+}
@@ -5,30 +5,244 @@ package frala
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/StroblIndustries/coreutils"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
-// ReadConfig reads any frala.json file and update the Config
+// ReadConfig reads any frala.json file and update the Config of the default Engine
 func ReadConfig() error {
-	if configContent, readErr := ioutil.ReadFile("frala.json"); readErr == nil { // Read the frala.json configuration, set any err to readErr
-		decodeErr := json.Unmarshal(configContent, &Config)
+	return Default().ReadConfig()
+}
+
+// SaveConfig saves the Config of the default Engine to frala.json
+func SaveConfig() error {
+	return Default().SaveConfig()
+}
+
+// ReadConfig reads the config file of the Engine and updates its Config
+func (e *Engine) ReadConfig() error {
+	if configContent, readErr := ioutil.ReadFile(e.configFile); readErr == nil { // Read the configuration, set any err to readErr
+		decodeErr := json.Unmarshal(configContent, e.Config)
 
 		if decodeErr != nil { // Decode configContent into Config
-			decodeErr = errors.New("Unable to decode frala.json: " + decodeErr.Error())
+			decodeErr = fmt.Errorf("Unable to decode %s: %w", e.configFile, decodeErr)
+		} else if languageErr := e.canonicalizeConfig(); languageErr != nil { // Ensure each language is a valid tag, in its canonical form
+			decodeErr = fmt.Errorf("Invalid languages in %s:\n%w", e.configFile, languageErr)
+		} else if checkErr := e.CheckTerms(); checkErr != nil { // Ensure each value is valid MessageFormat syntax
+			decodeErr = fmt.Errorf("Invalid Terms in %s:\n%w", e.configFile, checkErr)
 		}
 
 		return decodeErr
 	} else { // If there was a read error
-		return errors.New("Failed to read frala.json: " + readErr.Error())
+		return fmt.Errorf("Failed to read %s: %w", e.configFile, readErr)
 	}
 }
 
-// SaveConfig saves the Config to frala.json
-func SaveConfig() error {
-	if configContent, encodeErr := json.MarshalIndent(Config, "", "\t"); encodeErr == nil { // Encode the Config into configContent, ensure it maintains pretty formatting.
-		return coreutils.WriteOrUpdateFile("frala.json", configContent, coreutils.NonGlobalFileMode) // Attempt to write the configContent to frala.json
-	} else { // If we failed to encode the Config to JSON
-		return errors.New("Failed to encode the Config to JSON: " + encodeErr.Error())
+// ReloadConfig reads the config file of the Engine again, replacing its Config, such as when the file changes while watching
+// Unlike ReadConfig, Terms and other settings removed from the file are removed from the Config. The Config is left unchanged if the file is invalid.
+// This is safe to call while files are being parsed, which keep using the settings the Config had when they started.
+func (e *Engine) ReloadConfig() error {
+	fresh := &Engine{Config: &ConfigOptions{}, configFile: e.configFile}
+
+	if readErr := fresh.ReadConfig(); readErr != nil {
+		return readErr
 	}
+
+	fresh.setDefaults()
+
+	e.termsLock.Lock()
+	*e.Config = *fresh.Config
+	e.termsLock.Unlock()
+
+	return nil
+}
+
+// config gets a copy of the settings of the Config, so they can be read while ReloadConfig replaces the Config
+// The copy shares its Terms with the Config, which must still be read under the lock of the Config. Must not be called while holding that lock.
+func (e *Engine) config() ConfigOptions {
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	return *e.Config
+}
+
+// SaveConfig saves the Config of the Engine to its config file
+// The Config is written to a temporary file which then replaces the config file, so the config file is never left partially written.
+func (e *Engine) SaveConfig() error {
+	e.termsLock.RLock()
+	configContent, encodeErr := json.MarshalIndent(e.Config, "", "\t") // Encode the Config into configContent, ensure it maintains pretty formatting.
+	e.termsLock.RUnlock()
+
+	if encodeErr != nil { // If we failed to encode the Config to JSON
+		return fmt.Errorf("Failed to encode the Config to JSON: %w", encodeErr)
+	}
+
+	return writeFileAtomic(e.configFile, configContent)
+}
+
+// writeFileAtomic writes a file by writing a temporary file in the same directory and renaming it over the file
+// The file keeps its permissions if it already exists.
+func writeFileAtomic(file string, content []byte) error {
+	fileMode := os.FileMode(coreutils.NonGlobalFileMode)
+
+	if fileInfo, statErr := os.Stat(file); statErr == nil { // If the file exists, keep its permissions
+		fileMode = fileInfo.Mode().Perm()
+	}
+
+	tempFile, createErr := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file)+".*.tmp")
+
+	if createErr != nil {
+		return fmt.Errorf("Failed to write %s: %w", file, createErr)
+	}
+
+	tempName := tempFile.Name()
+	_, writeErr := tempFile.Write(content)
+
+	if writeErr == nil {
+		writeErr = tempFile.Sync() // Ensure the content is on disk before it replaces the file
+	}
+
+	if closeErr := tempFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+
+	if writeErr == nil {
+		writeErr = os.Chmod(tempName, fileMode)
+	}
+
+	if writeErr == nil {
+		writeErr = os.Rename(tempName, file)
+	}
+
+	if writeErr != nil { // If the file could not be replaced, remove the temporary file
+		os.Remove(tempName)
+		return fmt.Errorf("Failed to write %s: %w", file, writeErr)
+	}
+
+	return nil
+}
+
+// canonicalizeConfig canonicalizes every language of the Config, so en_GB and en-GB are the same language
+// Returns an error describing each language which is not a valid tag, and each Term with values for two forms of the same language.
+func (e *Engine) canonicalizeConfig() error {
+	var problems []string
+
+	canonical := func(language, where string) string { // Canonicalize a language, recording it as a problem if it isn't a valid tag
+		tag, tagErr := ParseTag(language)
+
+		if tagErr != nil {
+			problems = append(problems, where+": "+tagErr.Error())
+			return strings.TrimSpace(language)
+		}
+
+		return tag.String()
+	}
+
+	if e.Config.DefaultLanguage != "" {
+		e.Config.DefaultLanguage = canonical(e.Config.DefaultLanguage, "DefaultLanguage")
+	}
+
+	if e.Config.CurrentLanguage != "" {
+		e.Config.CurrentLanguage = canonical(e.Config.CurrentLanguage, "CurrentLanguage")
+	}
+
+	var languages []string
+
+	for _, language := range e.Config.Languages { // For each language, removing any which are now duplicates
+		language = canonical(language, "Languages")
+
+		if !containsString(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+
+	e.Config.Languages = languages
+
+	if e.Config.Fallbacks != nil {
+		fallbacks := make(map[string][]string)
+
+		for language, chain := range e.Config.Fallbacks {
+			canonicalChain := make([]string, len(chain))
+
+			for index, fallback := range chain {
+				canonicalChain[index] = canonical(fallback, "Fallbacks of "+language)
+			}
+
+			fallbacks[canonical(language, "Fallbacks")] = canonicalChain
+		}
+
+		e.Config.Fallbacks = fallbacks
+	}
+
+	if e.Config.Directions != nil {
+		directions := make(map[string]string)
+
+		for language, direction := range e.Config.Directions { // For each override, ensuring it is either ltr or rtl
+			direction = strings.ToLower(strings.TrimSpace(direction))
+
+			if direction != DirectionLTR && direction != DirectionRTL {
+				problems = append(problems, "Directions of "+language+": "+direction+" is not ltr or rtl")
+			}
+
+			directions[canonical(language, "Directions")] = direction
+		}
+
+		e.Config.Directions = directions
+	}
+
+	e.termsLock.Lock()
+
+	for termName, term := range e.Config.Terms { // For each Term, ensure its values are keyed by canonical languages
+		canonicalTerm := make(Term)
+		var languages []string
+
+		for language := range term {
+			languages = append(languages, language)
+		}
+
+		sort.Strings(languages) // Sort the languages, so conflicting values are resolved consistently
+
+		for _, language := range languages { // Values of languages which are already canonical take precedence
+			if canonical(language, "Term "+termName) == language {
+				canonicalTerm[language] = term[language]
+			}
+		}
+
+		for _, language := range languages { // For each value of a language which isn't canonical
+			canonicalLanguage := CanonicalLanguage(language)
+
+			if _, exists := canonicalTerm[canonicalLanguage]; exists && canonicalLanguage != language { // If another form of the language was already provided
+				problems = append(problems, "Term "+termName+": has values for both "+language+" and another form of "+canonicalLanguage)
+			} else if !exists {
+				canonicalTerm[canonicalLanguage] = term[language]
+			}
+		}
+
+		e.Config.Terms[termName] = canonicalTerm
+	}
+
+	e.termsLock.Unlock()
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems) // Sort the problems, so they are reported in a consistent order
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// containsString returns whether the strings contain the value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
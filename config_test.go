@@ -0,0 +1,139 @@
+// This file contains the tests of reading, reloading and saving the Config
+
+package frala
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestReloadConfigWhileParsing ensures reloading the Config while files are parsed doesn't race, which go test -race reports
+func TestReloadConfigWhileParsing(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello"}}}`, map[string]string{
+		"page.html": `{{ type="term" src="hello" }} {{ type="term" src="frala.Direction" }} {{ type="term" src="frala.Languages" }}`,
+	})
+
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < 4; worker++ { // Parse and negotiate concurrently with the reloads
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for iteration := 0; iteration < 50; iteration++ {
+				engine.ParseWith(filepath.Join(dir, "page.html"), RenderOptions{Language: "fi"})
+				engine.Negotiate("fi")
+			}
+		}()
+	}
+
+	for iteration := 0; iteration < 50; iteration++ {
+		if reloadErr := engine.ReloadConfig(); reloadErr != nil {
+			t.Fatalf("ReloadConfig: %v", reloadErr)
+		}
+	}
+
+	wg.Wait()
+}
+
+// TestReloadConfigRemovesTerms ensures Terms removed from the file are removed from the Config, and an invalid file leaves it unchanged
+func TestReloadConfigRemovesTerms(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hello"}, "bye": {"en": "Bye"}}}`, nil)
+
+	writeTestFiles(t, dir, map[string]string{"frala.json": `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hi"}}}`})
+
+	if reloadErr := engine.ReloadConfig(); reloadErr != nil {
+		t.Fatalf("ReloadConfig: %v", reloadErr)
+	}
+
+	if _, exists := engine.Config.Terms["bye"]; exists {
+		t.Errorf("bye still exists after being removed from the config")
+	}
+
+	if got := engine.GetValue("hello", "en"); got != "Hi" {
+		t.Errorf("GetValue(hello) = %q, want %q", got, "Hi")
+	}
+
+	writeTestFiles(t, dir, map[string]string{"frala.json": `{"Terms": `})
+
+	if reloadErr := engine.ReloadConfig(); reloadErr == nil {
+		t.Errorf("ReloadConfig of an invalid config succeeded")
+	}
+
+	if got := engine.GetValue("hello", "en"); got != "Hi" {
+		t.Errorf("GetValue(hello) after an invalid reload = %q, want %q", got, "Hi")
+	}
+}
+
+// TestSaveConfigFailure ensures a config file which can't be replaced is left intact, without leaving temporary files behind
+func TestSaveConfigFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string) // Prevent the config file from being replaced
+	}{
+		{"read-only directory", func(t *testing.T, dir string) {
+			if chmodErr := os.Chmod(dir, 0555); chmodErr != nil {
+				t.Fatal(chmodErr)
+			}
+
+			t.Cleanup(func() { os.Chmod(dir, 0755) })
+
+			if probe, createErr := os.Create(filepath.Join(dir, "probe")); createErr == nil { // If permissions aren't enforced, such as for root
+				probe.Close()
+				os.Remove(probe.Name())
+				t.Skip("The directory is still writable")
+			}
+		}},
+		{"directory in place of the config", func(t *testing.T, dir string) {
+			if removeErr := os.Remove(filepath.Join(dir, "frala.json")); removeErr != nil {
+				t.Fatal(removeErr)
+			}
+
+			writeTestFiles(t, dir, map[string]string{"frala.json/kept": "Kept"}) // Renaming a file over a directory fails
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hello"}}}`, nil)
+			test.setup(t, dir)
+			before := snapshotDir(t, dir)
+			engine.SetValue("hello", "en", "Hi")
+
+			if saveErr := engine.SaveConfig(); saveErr == nil {
+				t.Fatal("SaveConfig succeeded")
+			}
+
+			if after := snapshotDir(t, dir); !reflect.DeepEqual(after, before) {
+				t.Errorf("Files after the failed save = %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+// snapshotDir gets the content of each file in dir, keyed by its slash-separated path
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+
+	walkErr := filepath.Walk(dir, func(file string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil || info.IsDir() {
+			return walkErr
+		}
+
+		content, readErr := os.ReadFile(file)
+		rel, _ := filepath.Rel(dir, file)
+		files[filepath.ToSlash(rel)] = string(content)
+		return readErr
+	})
+
+	if walkErr != nil {
+		t.Fatal(walkErr)
+	}
+
+	return files
+}
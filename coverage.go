@@ -0,0 +1,87 @@
+// This file contains the translation coverage of each language of the Config
+
+package frala
+
+// Coverage is how completely a language is translated, over the Terms of the Config
+// When weighted, each Term counts once for each time the templates use it, rather than once.
+type Coverage struct {
+	Language          string  // Language of the Coverage
+	Total             int     // Total is the number of Terms, or the number of uses of Terms when weighted
+	Translated        int     // Translated is the number of Terms with a value in the language
+	Fallback          int     // Fallback is the number of Terms without a value in the language which fall back to another language
+	Missing           int     // Missing is the number of Terms without a value in the language or any of its fallbacks
+	TranslatedPercent float64 // TranslatedPercent is the percentage of the Total which is Translated, from 0 to 100
+	FallbackPercent   float64 // FallbackPercent is the percentage of the Total which falls back to another language
+	MissingPercent    float64 // MissingPercent is the percentage of the Total which is Missing
+}
+
+// GetCoverage gets how completely each of the Languages of the Config of the default Engine is translated
+func GetCoverage(files []string) ([]Coverage, error) {
+	return Default().Coverage(files)
+}
+
+// Coverage gets how completely each of the Languages of the Config is translated over the Terms of the Config, in the order of the Languages
+// If templates are provided, each Term is weighted by how often they, and the Fragments they import, use it, so Terms they don't use are not counted.
+// The usages of a Fragment count once for each time it is imported.
+// Returns an error if a template could not be read.
+func (e *Engine) Coverage(files []string) ([]Coverage, error) {
+	var weights map[string]int
+
+	if len(files) != 0 { // If Terms should be weighted by their uses
+		l := newLinter(e)
+		l.weighted = true // A Fragment imported by several templates counts for each of them
+
+		if lintErr := l.lintFiles(files); lintErr != nil {
+			return nil, lintErr
+		}
+
+		weights = make(map[string]int)
+
+		for termName, usages := range l.usages {
+			weights[termName] = len(usages)
+		}
+	}
+
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	var coverages []Coverage
+
+	for _, language := range e.Config.availableLanguages() { // For each language
+		coverage := Coverage{Language: language}
+
+		for termName, term := range e.Config.Terms { // For each Term, counting it by its weight
+			weight := 1
+
+			if weights != nil {
+				weight = weights[termName]
+			}
+
+			coverage.Total += weight
+
+			if _, translated := term[language]; translated {
+				coverage.Translated += weight
+			} else if _, hasFallback := e.Config.termFallback(term, language); hasFallback {
+				coverage.Fallback += weight
+			} else {
+				coverage.Missing += weight
+			}
+		}
+
+		coverage.TranslatedPercent = percentage(coverage.Translated, coverage.Total)
+		coverage.FallbackPercent = percentage(coverage.Fallback, coverage.Total)
+		coverage.MissingPercent = percentage(coverage.Missing, coverage.Total)
+		coverages = append(coverages, coverage)
+	}
+
+	return coverages, nil
+}
+
+// percentage gets count as a percentage of total, which is 0 if total is 0
+func percentage(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(count) * 100 / float64(total)
+}
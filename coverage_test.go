@@ -0,0 +1,77 @@
+// This file contains the tests of the translation coverage of each language
+
+package frala
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCoverage ensures Terms are counted as translated, falling back or missing, and weighted by their uses in templates and the Fragments they import
+func TestCoverage(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi", "fr"], "Fallbacks": {"fr": ["fi"]}, "Terms": {
+		"hello": {"en": "Hello", "fi": "Hei"},
+		"menu": {"en": "Menu"},
+		"unused": {"en": "Unused", "fi": "Käyttämätön", "fr": "Inutilisé"}
+	}}`, map[string]string{
+		"header.html": `{{ type="term" src="menu" }}`,
+		"index.html":  `{{ type="fragment" src="header.html" }} {{ type="term" src="hello" }}`,
+		"about.html":  `{{ type="fragment" src="header.html" }}`,
+	})
+
+	tests := []struct {
+		name  string
+		files []string
+		want  []Coverage // Only the counts are compared
+	}{
+		{
+			name: "unweighted",
+			want: []Coverage{
+				{Language: "en", Total: 3, Translated: 3},
+				{Language: "fi", Total: 3, Translated: 2, Fallback: 1}, // menu falls back to the DefaultLanguage
+				{Language: "fr", Total: 3, Translated: 1, Fallback: 2},
+			},
+		},
+		{
+			name:  "weighted",
+			files: []string{"index.html", "about.html"},
+			want: []Coverage{ // menu is used by the header imported by both templates, and hello by one
+				{Language: "en", Total: 3, Translated: 3},
+				{Language: "fi", Total: 3, Translated: 1, Fallback: 2},
+				{Language: "fr", Total: 3, Fallback: 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var files []string
+
+			for _, file := range test.files {
+				files = append(files, filepath.Join(dir, file))
+			}
+
+			coverages, coverageErr := engine.Coverage(files)
+
+			if coverageErr != nil {
+				t.Fatal(coverageErr)
+			}
+
+			if len(coverages) != len(test.want) {
+				t.Fatalf("Coverage = %+v, want %+v", coverages, test.want)
+			}
+
+			for index, coverage := range coverages {
+				want := test.want[index]
+
+				if coverage.Language != want.Language || coverage.Total != want.Total || coverage.Translated != want.Translated || coverage.Fallback != want.Fallback || coverage.Missing != want.Missing {
+					t.Errorf("Coverage = %+v, want %+v", coverage, want)
+				}
+			}
+		})
+	}
+
+	if _, coverageErr := engine.Coverage([]string{filepath.Join(dir, "missing.html")}); coverageErr == nil {
+		t.Errorf("Coverage of a missing template succeeded")
+	}
+}
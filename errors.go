@@ -0,0 +1,195 @@
+// This file contains the errors reported while parsing
+
+package frala
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind is the kind of problem a ParseError describes
+type ErrorKind int
+
+const (
+	// ErrorSyntax is malformed Frala syntax, such as an unterminated {{
+	ErrorSyntax ErrorKind = iota
+
+	// ErrorMissingSource is Frala syntax without a src attribute
+	ErrorMissingSource
+
+	// ErrorMissingType is Frala syntax without a type attribute
+	ErrorMissingType
+
+	// ErrorInvalidType is Frala syntax with a type other than fragment or term
+	ErrorInvalidType
+
+	// ErrorUntranslated is a term without a value for the language being parsed
+	ErrorUntranslated
+
+	// ErrorFragmentRead is a fragment that could not be read
+	ErrorFragmentRead
+
+	// ErrorSelfImport is a fragment that imports itself
+	ErrorSelfImport
+
+	// ErrorOutsideRoot is a fragment that resolves outside of the root of the Engine, directly or through a symlink
+	ErrorOutsideRoot
+
+	// ErrorIncludeCycle is a fragment that imports a file which is already importing it, such as a.html → b.html → a.html
+	ErrorIncludeCycle
+
+	// ErrorIncludeDepth is a fragment nested deeper than the MaxIncludeDepth of the Engine
+	ErrorIncludeDepth
+
+	// ErrorPlural is a term with plural forms and a missing or invalid count, or no form for the category of the count
+	ErrorPlural
+
+	// ErrorPlaceholderMissing is a {name} placeholder of a term without a value from the attributes or Data
+	ErrorPlaceholderMissing
+
+	// ErrorPlaceholderUnused is an attribute of a term which is not a placeholder of its value
+	ErrorPlaceholderUnused
+
+	// ErrorMessage is a term value with invalid MessageFormat syntax, or an argument with an invalid value such as a plural argument which isn't a number
+	ErrorMessage
+
+	// ErrorInvalidLanguage is a term with a lang attribute which is not a valid BCP 47 language tag or POSIX locale identifier
+	ErrorInvalidLanguage
+
+	// ErrorUndefinedTerm is a term which is not defined in the Terms of the Config, reported by Lint
+	ErrorUndefinedTerm
+
+	// ErrorUnusedTerm is a Term of the Config which no template uses, reported by Lint
+	ErrorUnusedTerm
+
+	// ErrorMissingTranslation is a Term used by a template without a value for one of the Languages of the Config, reported by Lint
+	ErrorMissingTranslation
+
+	// ErrorUnknownAttribute is an attribute which has no effect, such as an attribute of a fragment or an argument for a placeholder no value of the term has, reported by Lint
+	ErrorUnknownAttribute
+)
+
+// errorKindNames are the names of each ErrorKind, used by String
+var errorKindNames = []string{
+	ErrorSyntax:             "syntax",
+	ErrorMissingSource:      "missing-source",
+	ErrorMissingType:        "missing-type",
+	ErrorInvalidType:        "invalid-type",
+	ErrorUntranslated:       "untranslated",
+	ErrorFragmentRead:       "fragment-read",
+	ErrorSelfImport:         "self-import",
+	ErrorOutsideRoot:        "outside-root",
+	ErrorIncludeCycle:       "include-cycle",
+	ErrorIncludeDepth:       "include-depth",
+	ErrorPlural:             "plural",
+	ErrorPlaceholderMissing: "placeholder-missing",
+	ErrorPlaceholderUnused:  "placeholder-unused",
+	ErrorMessage:            "message",
+	ErrorInvalidLanguage:    "invalid-language",
+	ErrorUndefinedTerm:      "undefined-term",
+	ErrorUnusedTerm:         "unused-term",
+	ErrorMissingTranslation: "missing-translation",
+	ErrorUnknownAttribute:   "unknown-attribute",
+}
+
+// String returns the name of the ErrorKind
+func (k ErrorKind) String() string {
+	if int(k) < len(errorKindNames) {
+		return errorKindNames[k]
+	}
+
+	return "ErrorKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// ParseErrorKind gets the ErrorKind with a name, such as unused-term
+func ParseErrorKind(name string) (ErrorKind, error) {
+	for kind, kindName := range errorKindNames {
+		if kindName == name {
+			return ErrorKind(kind), nil
+		}
+	}
+
+	return 0, errors.New(name + " is not a kind of problem. Kinds are: " + strings.Join(errorKindNames, ", "))
+}
+
+// ParseError is a diagnostic for a problem found while parsing, with the position it occurred at
+type ParseError struct {
+	File    string    // File the problem occurred in
+	Line    int       // Line number, starting at 1
+	Column  int       // Column number in runes, starting at 1
+	Tag     string    // Tag is the raw Frala syntax the problem occurred in, if any
+	Kind    ErrorKind // Kind of problem
+	Message string    // Message describing the problem
+}
+
+// Error returns the ParseError as file:line:column: message, or file: message if it has no position
+func (e *ParseError) Error() string {
+	if e.Line == 0 { // If the problem isn't at a position, such as a Term of a Config which isn't a file
+		return e.File + ": " + e.Message
+	}
+
+	return e.File + ":" + strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column) + ": " + e.Message
+}
+
+// newParseError creates a ParseError for a Node declared in file
+func newParseError(file string, node Node, kind ErrorKind, message string) ParseError {
+	return ParseError{
+		File:    file,
+		Line:    node.Pos.Line,
+		Column:  node.Pos.Column,
+		Tag:     node.Text,
+		Kind:    kind,
+		Message: message,
+	}
+}
+
+// MessageError is a syntax error in an ICU MessageFormat value
+type MessageError struct {
+	Offset  int    // Byte offset in the value the error occurred at
+	Message string // Message describing the error
+}
+
+// Error returns the MessageError as a message with its offset
+func (e *MessageError) Error() string {
+	return "offset " + strconv.Itoa(e.Offset) + ": " + e.Message
+}
+
+// ArgumentError is an argument of an ICU MessageFormat value with an invalid value, such as a plural argument which isn't a number
+type ArgumentError struct {
+	Argument string // Name of the argument
+	Value    string // Value of the argument
+	Err      error  // Err is why the value is invalid
+}
+
+// Error returns the ArgumentError as a message
+func (e *ArgumentError) Error() string {
+	return "Argument " + e.Argument + " has an invalid value " + strconv.Quote(e.Value) + ": " + e.Err.Error()
+}
+
+// Unwrap returns why the value is invalid
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// TagError is an invalid BCP 47 language tag or POSIX locale identifier
+type TagError struct {
+	Tag     string // Tag as it was provided
+	Message string // Message describing why the tag is invalid
+}
+
+// Error returns the TagError as a message
+func (e *TagError) Error() string {
+	return e.Tag + " is not a valid language tag: " + e.Message
+}
+
+// RootError is the error for a path that resolves outside of the root of an Engine
+type RootError struct {
+	Path string // Path as it was provided
+	Root string // Root the path must resolve inside of
+}
+
+// Error returns the RootError as a message
+func (e *RootError) Error() string {
+	return e.Path + " resolves outside of the root " + e.Root
+}
@@ -0,0 +1,159 @@
+// This file contains functionality for falling back to other languages when a Term is not translated
+
+package frala
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Fallback is a Term used in another language because it was not translated into the language being parsed
+type Fallback struct {
+	Term     string // Term which was not translated
+	Language string // Language the Term was not translated into
+	Used     string // Language of the value which was used instead
+	File     string // File the Term was used in
+	Line     int    // Line number, starting at 1
+	Column   int    // Column number in runes, starting at 1
+}
+
+// String returns the Fallback as file:line:column: message
+func (f Fallback) String() string {
+	return f.File + ":" + strconv.Itoa(f.Line) + ":" + strconv.Itoa(f.Column) + ": Term " + f.Term + " is not translated into " + f.Language + ", using " + f.Used
+}
+
+// FallbackChain gets the languages Terms are looked up in for a language of the default Engine
+func FallbackChain(language string) []string {
+	return Default().FallbackChain(language)
+}
+
+// Fallbacks gets every Fallback taken while parsing with the default Engine
+func Fallbacks() []Fallback {
+	return Default().Fallbacks()
+}
+
+// FallbackChain gets the languages Terms are looked up in for a language, in order
+// The chain starts with the language itself, followed by its Fallbacks from the Config, or its parent (pt for pt-BR) if it has none configured.
+// Each language in the chain is followed by its own fallbacks in turn, and the DefaultLanguage is always the final step.
+func (e *Engine) FallbackChain(language string) []string {
+	config := e.config()
+	return config.fallbackChain(language, true)
+}
+
+// fallbackChain gets the languages Terms are looked up in for a language, optionally with the final DefaultLanguage step
+func (c *ConfigOptions) fallbackChain(language string, withDefault bool) []string {
+	var chain []string
+	seen := make(map[string]bool)
+
+	var add func(string)
+	add = func(language string) {
+		if language == "" || seen[language] { // If there is no language, or it is already in the chain (preventing loops)
+			return
+		}
+
+		seen[language] = true
+		chain = append(chain, language)
+
+		if fallbacks, configured := c.Fallbacks[language]; configured { // If the Config provides the fallbacks of this language
+			for _, fallback := range fallbacks {
+				add(CanonicalLanguage(fallback))
+			}
+		} else { // Fall back to the base language, if it has a region or other subtag
+			add(baseLanguage(language))
+		}
+	}
+
+	add(CanonicalLanguage(language))
+
+	if withDefault && !containsString(chain, c.DefaultLanguage) { // The DefaultLanguage is always the final step
+		chain = append(chain, c.DefaultLanguage)
+	}
+
+	return chain
+}
+
+// baseLanguage gets the parent of a language, such as pt for pt-BR or zh-Hant for zh-Hant-TW, or an empty string if it has none
+func baseLanguage(language string) string {
+	tag, tagErr := ParseTag(language)
+
+	if tagErr != nil { // If the language isn't a valid tag, it has no parent
+		return ""
+	}
+
+	return tag.Parent().String()
+}
+
+// lookupFallback gets the value of a Term in the first language of the FallbackChain of a language it is translated into
+// Returns the value, the language it was found in and whether it was found in any language.
+func (e *Engine) lookupFallback(termName, language string) (Value, string, bool) {
+	for _, chainLanguage := range e.FallbackChain(language) { // For each language in the chain, until one is translated
+		if value, exists := e.lookupValue(termName, chainLanguage); exists {
+			return value, chainLanguage, true
+		}
+	}
+
+	return Value{}, "", false
+}
+
+// termFallback gets the first fallback of a language a Term is translated into, without the language itself
+// Unlike lookupFallback, this never sets the Term or takes the lock of the Config, so the caller may hold it.
+func (c *ConfigOptions) termFallback(term Term, language string) (string, bool) {
+	for _, fallback := range c.fallbackChain(language, true)[1:] { // For each fallback, until one is translated
+		if _, translated := term[fallback]; translated {
+			return fallback, true
+		}
+	}
+
+	return "", false
+}
+
+// recordFallback adds a Fallback to the report of the Engine
+func (e *Engine) recordFallback(fallback Fallback) {
+	e.fallbacksLock.Lock()
+	defer e.fallbacksLock.Unlock()
+
+	if e.fallbacks == nil {
+		e.fallbacks = make(map[Fallback]bool)
+	}
+
+	e.fallbacks[fallback] = true
+}
+
+// Fallbacks gets every Fallback taken while parsing, such as during a build, sorted by file, position, Term and language
+// Each Fallback is only reported once, even if the file was parsed multiple times.
+func (e *Engine) Fallbacks() []Fallback {
+	e.fallbacksLock.Lock()
+	defer e.fallbacksLock.Unlock()
+
+	fallbacks := make([]Fallback, 0, len(e.fallbacks))
+
+	for fallback := range e.fallbacks { // For each Fallback
+		fallbacks = append(fallbacks, fallback)
+	}
+
+	sort.Slice(fallbacks, func(i, j int) bool {
+		a, b := fallbacks[i], fallbacks[j]
+
+		if a.File != b.File {
+			return a.File < b.File
+		} else if a.Line != b.Line {
+			return a.Line < b.Line
+		} else if a.Column != b.Column {
+			return a.Column < b.Column
+		} else if a.Term != b.Term {
+			return a.Term < b.Term
+		}
+
+		return a.Language < b.Language
+	})
+
+	return fallbacks
+}
+
+// ClearFallbacks clears the Fallbacks of the Engine, such as before a new build
+func (e *Engine) ClearFallbacks() {
+	e.fallbacksLock.Lock()
+	defer e.fallbacksLock.Unlock()
+
+	e.fallbacks = nil
+}
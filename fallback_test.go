@@ -0,0 +1,71 @@
+// This file contains the tests of falling back to other languages for untranslated Terms
+
+package frala
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFallbackChain ensures each language falls back through its configured Fallbacks or its parent, ending with the DefaultLanguage
+func TestFallbackChain(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Fallbacks": {"pt-BR": ["pt-PT"], "pt-PT": ["pt"], "gl": ["es", "pt"], "es": ["gl"]}}`, nil)
+
+	tests := map[string][]string{
+		"en":         {"en"},
+		"fi":         {"fi", "en"},
+		"en-GB":      {"en-GB", "en"},
+		"pt-BR":      {"pt-BR", "pt-PT", "pt", "en"}, // Configured, then the Fallbacks of each fallback
+		"pt_BR.UTF8": {"pt-BR", "pt-PT", "pt", "en"},
+		"gl":         {"gl", "es", "pt", "en"}, // es falling back to gl again is ignored
+		"zh-Hant-TW": {"zh-Hant-TW", "zh-Hant", "zh", "en"},
+	}
+
+	for language, want := range tests {
+		if got := engine.FallbackChain(language); !reflect.DeepEqual(got, want) {
+			t.Errorf("FallbackChain(%s) = %v, want %v", language, got, want)
+		}
+	}
+}
+
+// TestFallbacksReported ensures Terms which fall back are rendered in the fallback and reported once per position
+func TestFallbacksReported(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "pt-BR"], "Terms": {
+		"hello": {"en": "Hello", "pt": "Olá"},
+		"bye": {"en": "Bye"},
+		"yes": {"en": "Yes", "pt-BR": "Sim"}
+	}}`, nil)
+
+	template := `{{ type="term" src="hello" }} {{ type="term" src="bye" }} {{ type="term" src="yes" }}`
+
+	for iteration := 0; iteration < 2; iteration++ { // Parse twice, which reports each Fallback once
+		var content strings.Builder
+		diagnostics, _ := engine.RenderReader(&content, "page.html", strings.NewReader(template), RenderOptions{Language: "pt-BR"})
+
+		if content.String() != "Olá Bye Sim" || len(diagnostics) != 0 {
+			t.Errorf("RenderReader = %q, %v, want Olá Bye Sim", content.String(), diagnostics)
+		}
+	}
+
+	want := []string{
+		"page.html:1:1: Term hello is not translated into pt-BR, using pt",
+		"page.html:1:31: Term bye is not translated into pt-BR, using en",
+	}
+
+	var got []string
+
+	for _, fallback := range engine.Fallbacks() {
+		got = append(got, fallback.String())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fallbacks = %q, want %q", got, want)
+	}
+
+	engine.ClearFallbacks()
+
+	if fallbacks := engine.Fallbacks(); len(fallbacks) != 0 {
+		t.Errorf("Fallbacks after ClearFallbacks = %v, want none", fallbacks)
+	}
+}
@@ -0,0 +1,134 @@
+// This file contains functionality for resolving the files an Engine reads
+
+package frala
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// readFile reads a template or Fragment, from the FS of the Engine if it has one
+func (e *Engine) readFile(file string) ([]byte, error) {
+	if e.fsys != nil { // If we are reading from an FS
+		return fs.ReadFile(e.fsys, fsPath(file))
+	}
+
+	return ioutil.ReadFile(file)
+}
+
+// statFile gets the FileInfo of a template or Fragment, from the FS of the Engine if it has one
+func (e *Engine) statFile(file string) (fs.FileInfo, error) {
+	if e.fsys != nil { // If we are reading from an FS
+		return fs.Stat(e.fsys, fsPath(file))
+	}
+
+	return os.Stat(file)
+}
+
+// readError is the error for a template or Fragment which could not be read
+func readError(file string) error {
+	return errors.New("Failed to read: " + file)
+}
+
+// includePath gets the path identifying a file being parsed, for include cycle detection
+// This is the absolute path of the file, or its cleaned path when reading from an FS.
+func (e *Engine) includePath(file string) (string, error) {
+	if e.fsys != nil { // If we are reading from an FS
+		return fsPath(file), nil
+	}
+
+	return filepath.Abs(file)
+}
+
+// fsPath converts a file name to a path in an FS, which is always relative to the root of the FS
+func fsPath(file string) string {
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(file), "/"))
+}
+
+// resolveFragment resolves the src of a Fragment relative to the file it is declared in
+// Returns a RootError if the Fragment resolves outside of the root of the Engine (or its FS), including through a symlink
+func (e *Engine) resolveFragment(file, src string) (string, error) {
+	if e.fsys != nil { // If we are reading from an FS, the root is the root of the FS
+		fragmentFile := fsPath(src) // Absolute Fragments are relative to the root of the FS
+
+		if !strings.HasPrefix(src, "/") { // If the Fragment is relative, it is relative to the file it is declared in
+			fragmentFile = path.Join(path.Dir(fsPath(file)), src)
+		}
+
+		if !fs.ValidPath(fragmentFile) { // If the Fragment escapes the root of the FS, such as with ../
+			return "", &RootError{Path: src, Root: "."}
+		}
+
+		return fragmentFile, nil
+	}
+
+	fragmentFile := src
+
+	if !filepath.IsAbs(fragmentFile) { // If the Fragment is relative, it is relative to the file it is declared in
+		absFile, absErr := filepath.Abs(file)
+
+		if absErr != nil {
+			return "", absErr
+		}
+
+		fragmentFile = filepath.Join(filepath.Dir(absFile), src)
+	}
+
+	fragmentFile = filepath.Clean(fragmentFile)
+	withinRoot := false
+
+	if realFile, evalErr := filepath.EvalSymlinks(fragmentFile); evalErr == nil { // If the Fragment exists, check where it really is, catching symlinks leading outside the root
+		withinRoot = withinDirectory(e.root, realFile)
+	} else { // If the Fragment doesn't exist, check the path itself doesn't escape the root, such as with ../
+		withinRoot = withinDirectory(e.root, fragmentFile) || withinDirectory(e.rootPath, fragmentFile)
+	}
+
+	if !withinRoot {
+		return "", &RootError{Path: src, Root: e.rootPath}
+	}
+
+	return fragmentFile, nil
+}
+
+// withinDirectory returns whether the absolute path provided is inside the directory
+func withinDirectory(directory, path string) bool {
+	rel, relErr := filepath.Rel(directory, path)
+	return relErr == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// displayPath returns an absolute path relative to the root of the Engine, if it is inside of it
+func (e *Engine) displayPath(path string) string {
+	for _, root := range []string{e.rootPath, e.root} { // For each form of the root
+		if withinDirectory(root, path) {
+			rel, _ := filepath.Rel(root, path)
+			return rel
+		}
+	}
+
+	return path
+}
+
+// resolveTemplate resolves the name of a template, a rooted slash-separated path such as /about.html, inside of a directory
+// The directory defaults to the root of the Engine, or the root of its FS. Returns a RootError if the template resolves outside of either root.
+func (e *Engine) resolveTemplate(directory, name string) (string, error) {
+	if e.fsys != nil { // If we are reading from an FS, the directory is a path in the FS
+		return e.resolveFragment("", "/"+path.Join(fsPath(directory), name))
+	}
+
+	if directory == "" { // If no directory was provided
+		directory = e.rootPath
+	}
+
+	file, absErr := filepath.Abs(filepath.Join(directory, filepath.FromSlash(name)))
+
+	if absErr != nil {
+		return "", absErr
+	}
+
+	return e.resolveFragment(file, file)
+}
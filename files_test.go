@@ -0,0 +1,66 @@
+// This file contains the tests of resolving and reading templates and Fragments
+
+package frala
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFragmentConfinedToRoot ensures Fragments are only imported from inside the root of the Engine, however their path escapes it
+func TestFragmentConfinedToRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"secret.html":          "Secret",
+		"site/shared.html":     "Shared",
+		"site/pages/part.html": "Part",
+	})
+
+	if symlinkErr := os.Symlink(filepath.Join(dir, "secret.html"), filepath.Join(dir, "site", "link.html")); symlinkErr != nil {
+		t.Skip("Symlinks are not supported: ", symlinkErr)
+	}
+
+	engine, newErr := New(Options{Config: &ConfigOptions{DefaultLanguage: "en"}, Root: filepath.Join(dir, "site")})
+
+	if newErr != nil {
+		t.Fatal(newErr)
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		content string
+		kind    ErrorKind // Kind of the Diagnostic, if content is empty
+	}{
+		{"relative", "part.html", "Part", 0},
+		{"parent inside the root", "../shared.html", "Shared", 0},
+		{"cleaned inside the root", "../pages/../shared.html", "Shared", 0},
+		{"parent outside the root", "../../secret.html", "", ErrorOutsideRoot},
+		{"through the root", "../../site/../secret.html", "", ErrorOutsideRoot},
+		{"absolute outside the root", filepath.Join(dir, "secret.html"), "", ErrorOutsideRoot},
+		{"absolute inside the root", filepath.Join(dir, "site", "shared.html"), "Shared", 0},
+		{"symlink outside the root", "../link.html", "", ErrorOutsideRoot},
+		{"missing", "missing.html", "", ErrorFragmentRead},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writeTestFiles(t, dir, map[string]string{"site/pages/page.html": `{{ type="fragment" src="` + test.src + `" }}`})
+			engine.Invalidate(filepath.Join(dir, "site", "pages", "page.html"))
+			response := engine.Parse(filepath.Join(dir, "site", "pages", "page.html"))
+
+			if response.Content != test.content {
+				t.Errorf("Parse = %q, want %q", response.Content, test.content)
+			}
+
+			if test.content != "" {
+				if len(response.Diagnostics) != 0 {
+					t.Errorf("Diagnostics = %v, want none", response.Diagnostics)
+				}
+			} else if len(response.Diagnostics) != 1 || response.Diagnostics[0].Kind != test.kind {
+				t.Errorf("Diagnostics = %v, want one of %s", response.Diagnostics, test.kind)
+			}
+		})
+	}
+}
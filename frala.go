@@ -2,23 +2,142 @@
 
 package frala
 
-// Config is the configuration of Frala
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+)
+
+// Config is the configuration of the default Engine, read from frala.json in the working directory when Frala is initialized
 var Config ConfigOptions
 
-// InitError is any potential error from initializing Frala
+// InitError is any potential error from reading the configuration of the default Engine when Frala is initialized
 var InitError error
 
-// CurrentParsingFile is the file we're currently parsing
-var CurrentParsingFile string
+// DefaultMaxIncludeDepth is the maximum number of nested Fragments when an Engine has no MaxIncludeDepth
+const DefaultMaxIncludeDepth = 32
+
+// defaultEngine is the Engine used by the package-level functions
+var defaultEngine *Engine
+
+// defaultEngineOnce ensures the default Engine is only created once
+var defaultEngineOnce sync.Once
+
+// Options are the options for creating an Engine
+type Options struct {
+	ConfigFile      string         // ConfigFile is the frala.json to read the Config from and save it to
+	Config          *ConfigOptions // Config to use as-is rather than reading ConfigFile, which is then only used by SaveConfig
+	Parallelism     int            // Parallelism is the number of files MultilingualParse parses concurrently. Defaults to the number of CPUs
+	Root            string         // Root is the directory Fragments must resolve inside of. Defaults to the directory of ConfigFile, or the working directory
+	MaxIncludeDepth int            // MaxIncludeDepth is the maximum number of nested Fragments. Defaults to DefaultMaxIncludeDepth
+	FS              fs.FS          // FS to read templates and Fragments from, such as an embed.FS, rather than the OS filesystem. Root is not used with an FS
+	Cache           *Cache         // Cache of parsed Documents, which may be shared between Engines. Defaults to a new Cache
+}
+
+// Engine parses files and converts Terms using its own Config, independent of any other Engine
+// Parsing is safe for concurrent use, provided Terms are only changed through the methods of the Engine.
+type Engine struct {
+	Config          *ConfigOptions    // Config of this Engine, including its Terms
+	Parallelism     int               // Parallelism is the number of files MultilingualParse parses concurrently. Defaults to the number of CPUs
+	MaxIncludeDepth int               // MaxIncludeDepth is the maximum number of nested Fragments. Defaults to DefaultMaxIncludeDepth
+	configFile      string            // File the Config is read from and saved to
+	root            string            // Absolute path of the directory Fragments must resolve inside of, with symlinks evaluated
+	rootPath        string            // Absolute path of the directory Fragments must resolve inside of, as provided
+	fsys            fs.FS             // FS to read templates and Fragments from, if any
+	cache           *Cache            // Cache of parsed Documents
+	termsLock       sync.RWMutex      // Lock guarding the Config, including its Terms
+	fallbacks       map[Fallback]bool // Fallbacks taken while parsing
+	fallbacksLock   sync.Mutex        // Lock guarding the Fallbacks
+}
+
+// New creates an Engine with the options provided, reading its Config from ConfigFile if no Config is provided
+func New(opts Options) (*Engine, error) {
+	engine := &Engine{Config: opts.Config, Parallelism: opts.Parallelism, MaxIncludeDepth: opts.MaxIncludeDepth, configFile: opts.ConfigFile, fsys: opts.FS, cache: opts.Cache}
+	root := opts.Root
+
+	if root == "" && opts.ConfigFile != "" { // If no Root was provided, default to the directory of the config file
+		root = filepath.Dir(opts.ConfigFile)
+	}
+
+	if engine.cache == nil { // If no Cache was provided
+		engine.cache = NewCache()
+	}
+
+	if rootErr := engine.setRoot(root); rootErr != nil {
+		return nil, rootErr
+	}
+
+	if engine.Config == nil { // If no Config was provided
+		engine.Config = &ConfigOptions{}
+
+		if opts.ConfigFile != "" { // If we should read the Config from a file
+			if readErr := engine.ReadConfig(); readErr != nil {
+				return nil, readErr
+			}
+		}
+	}
+
+	engine.setDefaults()
+	return engine, nil
+}
 
 func init() {
-	InitError = ReadConfig() // Read the config, setting it's content to Config and any error to readError
+	Default() // Create the default Engine, reading its Config and setting any error to InitError
+}
+
+// Default gets the Engine used by the package-level functions
+// Its Config is the package-level Config, read from frala.json in the working directory when Frala is initialized
+func Default() *Engine {
+	defaultEngineOnce.Do(func() {
+		defaultEngine = &Engine{Config: &Config, configFile: "frala.json", cache: NewCache()}
+		InitError = defaultEngine.ReadConfig() // Read the config, setting any error to InitError
+
+		if rootErr := defaultEngine.setRoot(""); rootErr != nil && InitError == nil { // Confine Fragments to the working directory
+			InitError = rootErr
+		}
+		defaultEngine.setDefaults()
+	})
+
+	return defaultEngine
+}
+
+// setRoot sets the directory Fragments must resolve inside of, defaulting to the working directory
+func (e *Engine) setRoot(root string) error {
+	if root == "" { // If no root was provided
+		root = "."
+	}
+
+	absRoot, absErr := filepath.Abs(root)
+
+	if absErr != nil { // If we failed to get the absolute path of the root
+		return fmt.Errorf("Failed to resolve root %s: %w", root, absErr)
+	}
+
+	e.root = absRoot
+	e.rootPath = absRoot
+
+	if realRoot, evalErr := filepath.EvalSymlinks(absRoot); evalErr == nil { // If the root exists, resolve any symlinks so fragments can be compared against it
+		e.root = realRoot
+	}
+
+	return nil
+}
+
+// setDefaults ensures the Config has a DefaultLanguage, CurrentLanguage, Direction and Terms, with canonical languages
+func (e *Engine) setDefaults() {
+	if e.Config.DefaultLanguage == "" { // If no DefaultLanguage was provided
+		e.Config.DefaultLanguage = "en" // Default language to English
+	}
+
+	if e.Config.CurrentLanguage == "" { // If no CurrentLanguage was provided
+		e.Config.CurrentLanguage = e.Config.DefaultLanguage // Set CurrentLanguage to default to DefaultLanguage
+	}
 
-	if Config.DefaultLanguage == "" { // If no DefaultLanguage was provided
-		Config.DefaultLanguage = "en" // Default language to English
+	if e.Config.Terms == nil { // If no Terms were provided
+		e.Config.Terms = make(map[string]Term)
 	}
 
-	Config.DefaultLanguage = Sanitize(Config.DefaultLanguage) // Sanitize the language
-	Config.CurrentLanguage = Config.DefaultLanguage           // Set CurrentLanguage to default to DefaultLanguage
-	Config.Direction = GetDirection(Config.DefaultLanguage)   // Get the likely direction of the DefaultLanguage
+	e.canonicalizeConfig()                                     // Ensure every language is canonical, ignoring invalid languages which ReadConfig reports
+	e.Config.Direction = e.Direction(e.Config.DefaultLanguage) // Get the likely direction of the DefaultLanguage
 }
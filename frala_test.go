@@ -0,0 +1,44 @@
+// This file contains helpers shared by the tests of Frala
+
+package frala
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestEngine writes a config and files into a temporary directory and creates an Engine from them
+// Returns the Engine and the directory, which is the root of the Engine.
+func newTestEngine(t *testing.T, config string, files map[string]string) (*Engine, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	writeTestFiles(t, dir, files)
+	writeTestFiles(t, dir, map[string]string{"frala.json": config})
+
+	engine, newErr := New(Options{ConfigFile: filepath.Join(dir, "frala.json")})
+
+	if newErr != nil {
+		t.Fatalf("New: %v", newErr)
+	}
+
+	return engine, dir
+}
+
+// writeTestFiles writes each file, keyed by its slash-separated path relative to dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+
+		if mkdirErr := os.MkdirAll(filepath.Dir(file), 0755); mkdirErr != nil {
+			t.Fatal(mkdirErr)
+		}
+
+		if writeErr := os.WriteFile(file, []byte(content), 0644); writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// This file contains functionality for rendering templates in response to HTTP requests
+
+package frala
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultIndex is the template rendered for a request to a directory when a Handler has no Index
+const DefaultIndex = "index.html"
+
+// Handler is an http.Handler rendering the templates under its Root in the language negotiated for each request
+// A request for /about.html renders Root/about.html, and a request for a directory renders its Index. Only .html templates are served, so serve any assets with an http.FileServer.
+// Templates with any Diagnostics respond with 500 so broken content never reaches users, unless ServeDiagnostics is set to serve them as well as they could be rendered.
+type Handler struct {
+	Engine           *Engine          // Engine rendering the templates, whose Cache keeps parsed templates between requests
+	Root             string           // Root is the directory templates are served from. Defaults to the root of the Engine, or the root of its FS
+	Index            string           // Index is the template rendered for a request to a directory. Defaults to DefaultIndex
+	Negotiate        NegotiateOptions // Negotiate are the options for negotiating the language of each request
+	LanguagePrefix   bool             // LanguagePrefix is whether a request path may start with one of the Languages of the Config, such as /fi/about.html, which wins over negotiation
+	ServeDiagnostics bool             // ServeDiagnostics is whether templates with Diagnostics are served as well as they could be rendered, with their Diagnostics logged, rather than responding with 500. Strict in the Config still responds with 500
+	Debug            bool             // Debug is whether the Diagnostics of a template are included in its 500 response, rather than only logged
+	ErrorLog         *log.Logger      // ErrorLog logs templates which fail to render or have Diagnostics. Defaults to the standard logger
+}
+
+// NewHandler creates a Handler rendering the templates under the root of the Engine
+func NewHandler(engine *Engine) *Handler {
+	return &Handler{Engine: engine}
+}
+
+// ServeHTTP renders the template of the request path in the negotiated language
+// Responds with 404 if there is no template, 405 for methods other than GET and HEAD, and 500 if the template fails to render, including for any Diagnostic unless ServeDiagnostics is set.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead { // Only templates can be served
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath, language := r.URL.Path, ""
+
+	if h.LanguagePrefix { // If the language may be the first segment of the path
+		if prefixLanguage, rest, hasPrefix := h.Engine.SplitLanguagePrefix(urlPath); hasPrefix {
+			urlPath, language = rest, prefixLanguage
+		}
+	}
+
+	file, fileErr := h.templateFile(urlPath)
+
+	if errors.Is(fileErr, errIsDirectory) { // If this is a directory, redirect so relative links resolve inside of it
+		location := path.Clean("/"+r.URL.Path) + "/" // Cleaning ensures the location can't start with //, which would be another host
+
+		if location == "//" { // If this is the root, which is already a directory
+			location = "/"
+		}
+
+		if r.URL.RawQuery != "" { // Keep the query, such as ?lang=fi
+			location += "?" + r.URL.RawQuery
+		}
+
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return
+	} else if fileErr != nil { // If there is no template for this path
+		http.NotFound(w, r)
+		return
+	}
+
+	if language == "" { // If the path didn't provide the language
+		language = h.Engine.NegotiateRequest(r, h.Negotiate)
+	}
+
+	var content bytes.Buffer
+	diagnostics, renderErr := h.Engine.Render(&content, file, RenderOptions{Language: language, Strict: !h.ServeDiagnostics})
+
+	w.Header().Set("Vary", "Accept-Language, Cookie") // The language depends on these headers, so caches must not share responses across them
+
+	if renderErr != nil { // If the template failed to render, including because of any Diagnostic unless they are served
+		h.serveError(w, file, diagnostics, renderErr)
+		return
+	} else if len(diagnostics) != 0 { // If the template rendered with problems and ServeDiagnostics is set, serve it as well as it could be rendered
+		h.logger().Printf("frala: rendered %s with problems:\n%s", file, strings.Join(problems(diagnostics, nil), "\n"))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", language)
+	w.Header().Set("Content-Length", strconv.Itoa(content.Len()))
+
+	if r.Method != http.MethodHead {
+		content.WriteTo(w)
+	}
+}
+
+// errIsDirectory is the error for a request path which is a directory without a trailing /
+var errIsDirectory = errors.New("Path is a directory")
+
+// templateFile gets the template of a request path, ensuring it is an HTML file inside the Root of the Handler and the root of the Engine
+func (h *Handler) templateFile(urlPath string) (string, error) {
+	if urlPath == "" { // If this is a language prefix without a trailing /, such as /fi
+		return "", errIsDirectory
+	}
+
+	name := path.Clean("/" + urlPath) // Cleaning a rooted path removes any ../, so the path can't escape the Root
+
+	if strings.HasSuffix(urlPath, "/") { // If this is a request for a directory
+		name = path.Join(name, h.index())
+	}
+
+	file, resolveErr := h.Engine.resolveTemplate(h.Root, name)
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	fileInfo, statErr := h.Engine.statFile(file)
+
+	if statErr != nil {
+		return "", statErr
+	} else if fileInfo.IsDir() {
+		return "", errIsDirectory
+	} else if path.Ext(name) != ".html" { // Only templates are served
+		return "", fs.ErrNotExist
+	}
+
+	return file, nil
+}
+
+// index gets the template rendered for a request to a directory
+func (h *Handler) index() string {
+	if h.Index == "" {
+		return DefaultIndex
+	}
+
+	return h.Index
+}
+
+// serveError responds with 500 for a template which failed to render, logging why
+func (h *Handler) serveError(w http.ResponseWriter, file string, diagnostics []ParseError, renderErr error) {
+	problems := problems(diagnostics, renderErr)
+	h.logger().Printf("frala: failed to render %s:\n%s", file, strings.Join(problems, "\n"))
+
+	message := http.StatusText(http.StatusInternalServerError)
+
+	if h.Debug { // If the problems should be shown, such as during development
+		message += "\n\n" + strings.Join(problems, "\n")
+	}
+
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// logger gets the logger of the Handler, defaulting to the standard logger
+func (h *Handler) logger() *log.Logger {
+	if h.ErrorLog == nil {
+		return log.Default()
+	}
+
+	return h.ErrorLog
+}
+
+// problems gets each Diagnostic of a template as a line, along with the error which failed it if that isn't already a Diagnostic
+func problems(diagnostics []ParseError, renderErr error) []string {
+	var problems []string
+
+	for _, diagnostic := range diagnostics { // For each Diagnostic
+		problems = append(problems, diagnostic.Error())
+	}
+
+	if renderErr != nil && len(diagnostics) == 0 { // If the error is not already a Diagnostic
+		problems = append(problems, renderErr.Error())
+	}
+
+	return problems
+}
@@ -0,0 +1,87 @@
+// This file contains the tests of serving templates over HTTP
+
+package frala
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestHandler ensures each request responds with the status, location and content expected
+func TestHandler(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello", "fi": "Hei"}}}`, map[string]string{
+		"index.html":      `<p>{{ type="term" src="hello" }}</p>`,
+		"docs/index.html": `<p>Docs</p>`,
+		"broken.html":     `<p>{{ type="fragment" src="missing.html" }}</p>`,
+		"style.css":       `p {}`,
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		lenient  bool
+		status   int
+		location string
+		content  string
+	}{
+		{name: "index", target: "/", status: http.StatusOK, content: "<p>Hello</p>"},
+		{name: "head", method: http.MethodHead, target: "/", status: http.StatusOK},
+		{name: "negotiated by query", target: "/?lang=fi", status: http.StatusOK, content: "<p>Hei</p>"},
+		{name: "language prefix", target: "/fi/", status: http.StatusOK, content: "<p>Hei</p>"},
+		{name: "missing", target: "/missing.html", status: http.StatusNotFound},
+		{name: "not a template", target: "/style.css", status: http.StatusNotFound},
+		{name: "escaping the root", target: "/../frala.json", status: http.StatusNotFound},
+		{name: "method", method: http.MethodPost, target: "/", status: http.StatusMethodNotAllowed},
+		{name: "directory", target: "/docs", status: http.StatusMovedPermanently, location: "/docs/"},
+		{name: "directory with query", target: "/docs?lang=fi", status: http.StatusMovedPermanently, location: "/docs/?lang=fi"},
+		{name: "directory with leading slashes", target: "//docs", status: http.StatusMovedPermanently, location: "/docs/"},
+		{name: "prefix without slash", target: "/fi", status: http.StatusMovedPermanently, location: "/fi/"},
+		{name: "diagnostics", target: "/broken.html", status: http.StatusInternalServerError}, // Broken content is never served by default
+		{name: "served diagnostics", target: "/broken.html", lenient: true, status: http.StatusOK, content: "<p></p>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logged strings.Builder
+			handler := NewHandler(engine)
+			handler.LanguagePrefix = true
+			handler.ServeDiagnostics = test.lenient
+			handler.ErrorLog = log.New(&logged, "", 0)
+
+			method := test.method
+
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			request := httptest.NewRequest(method, "http://example.com/", nil)
+			request.URL.Path, request.URL.RawQuery, _ = strings.Cut(test.target, "?") // Set the path as-is, so it isn't parsed as a host
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			response := recorder.Result()
+			body, _ := ioutil.ReadAll(response.Body)
+
+			if response.StatusCode != test.status {
+				t.Fatalf("status = %d, want %d: %s", response.StatusCode, test.status, body)
+			}
+
+			if location := response.Header.Get("Location"); location != test.location {
+				t.Errorf("Location = %q, want %q", location, test.location)
+			}
+
+			if test.content != "" && string(body) != test.content {
+				t.Errorf("content = %q, want %q", body, test.content)
+			}
+
+			if strings.HasSuffix(test.name, "diagnostics") && !strings.Contains(logged.String(), "missing.html") { // Diagnostics are logged, whether or not the template is served
+				t.Errorf("diagnostics were not logged: %q", logged.String())
+			}
+		})
+	}
+}
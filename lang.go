@@ -6,33 +6,94 @@ import (
 	"strings"
 )
 
-// GetDirection gets the likely direction of the language provided
-func GetDirection(language string) string {
-	direction := "ltr" // Default to Left-to-Right
-	rtlLanguages := []string{
-		"ar", // Arabic
-		"iw", // Hebrew
+// The directions of text
+const (
+	DirectionLTR = "ltr" // Left-to-right
+	DirectionRTL = "rtl" // Right-to-left
+)
+
+// rtlScripts are the ISO 15924 scripts written right-to-left, from the CLDR script metadata. Every other script is left-to-right
+var rtlScripts = map[string]bool{}
+
+// likelyScripts are the likely scripts of languages, and of languages in specific regions, from the CLDR likely subtags
+// Only languages whose likely script is right-to-left, or whose script differs by region, need to be listed. Every other language is likely written left-to-right.
+var likelyScripts = map[string]string{}
+
+// registerLikelyScript registers the likely script of each of the languages provided, which may include a region such as pa-PK
+func registerLikelyScript(script, languages string) {
+	for _, language := range strings.Split(languages, ",") { // For each language
+		likelyScripts[language] = script
 	}
+}
 
-	language = Sanitize(language)              // Sanitize the language
-	language = strings.ToLower(language)       // Lowercase the language
-	language = strings.Split(language, "_")[0] // If there is a special format, like en_GB, ensure we only get first part
+func init() {
+	for _, script := range strings.Split("Adlm,Arab,Aran,Armi,Avst,Chrs,Cprt,Elym,Hatr,Hebr,Hung,Khar,Lydi,Mand,Mani,Mend,Merc,Mero,Narb,Nbat,Nkoo,Orkh,Ougr,Palm,Phli,Phlp,Phnx,Prti,Rohg,Samr,Sarb,Sogd,Sogo,Syrc,Thaa,Yezi", ",") {
+		rtlScripts[script] = true
+	}
 
-	if strings.Contains(strings.Join(rtlLanguages, ",")+",", language+",") { // If this is a language specified in rtlLanguages
-		direction = "rtl" // Change direction to rtl
+	registerLikelyScript("Arab", "ar,arq,ars,ary,arz,acm,aeb,apc,apd,ajp,bal,bft,bgn,bqi,brh,ckb,fa,glk,haz,hnd,khw,ks,lki,lrc,luz,mzn,pnb,prd,prs,ps,sd,sdh,skr,ug,ur,wni,zdj")
+	registerLikelyScript("Arab", "az-IQ,az-IR,ha-CM,ha-SD,ky-CN,ms-CC,pa-PK,tg-PK,tk-AF,tk-IR,uz-AF")
+	registerLikelyScript("Hebr", "he,jpr,jrb,lad,yi")
+	registerLikelyScript("Syrc", "aii,syr")
+	registerLikelyScript("Thaa", "dv")
+	registerLikelyScript("Nkoo", "nqo")
+	registerLikelyScript("Mand", "myz")
+	registerLikelyScript("Samr", "smp")
+	registerLikelyScript("Rohg", "rhg")
+	registerLikelyScript("Deva", "sd-IN")
+}
+
+// likelyScript gets the likely script of a Tag, using its explicit script if it has one
+func likelyScript(tag Tag) string {
+	if tag.Script != "" {
+		return tag.Script
+	}
+
+	if script, exists := likelyScripts[tag.Language+"-"+tag.Region]; exists && tag.Region != "" { // If the script differs in the region
+		return script
 	}
 
-	return direction
+	return likelyScripts[tag.Language]
 }
 
-// Sanitize will ensure that language symbols are sanitized correctly
-func Sanitize(language string) string {
-	filterList := []string{"@"}   // Create a filter list of characters that need to be filtered / replaced
-	sanitizeList := []string{"-"} // Create a sanitize list of characters that coorespond with filterList
+// GetDirection gets the likely direction of the language provided, from the direction of its script or likely script
+// For example, ar, he, fa, ur, yi and ku-Arab are rtl, while ku, az and pa are ltr unless written in their Arabic script.
+func GetDirection(language string) string {
+	tag, _ := ParseTag(language)
+
+	if rtlScripts[likelyScript(tag)] { // If the language is likely written in a right-to-left script
+		return DirectionRTL
+	}
+
+	return DirectionLTR // Default to Left-to-Right
+}
+
+// Direction gets the direction of a language, using the Directions of the Config before GetDirection
+// An override for a language also applies to its more specific tags, so an override for ku applies to ku-TR unless ku-TR has its own.
+func (e *Engine) Direction(language string) string {
+	tag, tagErr := ParseTag(language)
+	directions := e.config().Directions
+
+	for tagErr == nil && tag.Language != "" { // For the language and each of its parents
+		if direction, overridden := directions[tag.String()]; overridden {
+			return direction
+		}
 
-	for index, filterChar := range filterList {
-		language = strings.Replace(language, filterChar, sanitizeList[index], -1) // Set language to replace the filterChar with the cooresponding char in sanitizeList
+		tag = tag.Parent()
 	}
 
-	return language
+	return GetDirection(language)
+}
+
+// primaryLanguage gets the canonical primary language of a language, such as pt for pt_BR or zh for zh-Hant-TW
+func primaryLanguage(language string) string {
+	tag, _ := ParseTag(language)
+	return tag.Language
+}
+
+// Sanitize will ensure that language symbols are sanitized correctly
+//
+// Deprecated: Use CanonicalLanguage, which Sanitize now calls.
+func Sanitize(language string) string {
+	return CanonicalLanguage(language)
 }
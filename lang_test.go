@@ -0,0 +1,83 @@
+// This file contains the tests of getting the direction of languages
+
+package frala
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGetDirection ensures the direction is that of the explicit or likely script of a language, including in specific regions
+func TestGetDirection(t *testing.T) {
+	tests := map[string]string{
+		"en":      DirectionLTR,
+		"ar":      DirectionRTL,
+		"ar-EG":   DirectionRTL,
+		"he":      DirectionRTL,
+		"iw":      DirectionRTL, // Deprecated code of he
+		"fa":      DirectionRTL,
+		"fa_IR":   DirectionRTL,
+		"ur":      DirectionRTL,
+		"yi":      DirectionRTL,
+		"dv":      DirectionRTL,
+		"ku":      DirectionLTR,
+		"ku-Arab": DirectionRTL,
+		"ckb":     DirectionRTL,
+		"pa":      DirectionLTR,
+		"pa-IN":   DirectionLTR,
+		"pa-PK":   DirectionRTL,
+		"az":      DirectionLTR,
+		"az-IR":   DirectionRTL,
+		"sd":      DirectionRTL,
+		"sd-IN":   DirectionLTR, // Devanagari in India
+		"uz-Arab": DirectionRTL,
+		"sr-Latn": DirectionLTR,
+		"":        DirectionLTR,
+		"!":       DirectionLTR,
+	}
+
+	for language, want := range tests {
+		if got := GetDirection(language); got != want {
+			t.Errorf("GetDirection(%q) = %s, want %s", language, got, want)
+		}
+	}
+}
+
+// TestEngineDirection ensures the Directions of the Config override the likely direction of a language and its more specific tags
+func TestEngineDirection(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Directions": {"ku": "RTL", "ku-TR": "ltr", "ar_MA": "ltr"}}`, nil)
+
+	tests := map[string]string{
+		"en":      DirectionLTR,
+		"ku":      DirectionRTL,
+		"ku-IQ":   DirectionRTL, // Overridden by ku
+		"ku-TR":   DirectionLTR, // Has its own override
+		"ku-Latn": DirectionRTL,
+		"ar":      DirectionRTL,
+		"ar-MA":   DirectionLTR,
+		"fa":      DirectionRTL, // Not overridden
+	}
+
+	for language, want := range tests {
+		if got := engine.Direction(language); got != want {
+			t.Errorf("Direction(%q) = %s, want %s", language, got, want)
+		}
+	}
+
+	if got := engine.Config.Direction; got != DirectionLTR {
+		t.Errorf("Config.Direction = %s, want %s", got, DirectionLTR)
+	}
+}
+
+// TestEngineDirectionInvalid ensures a Directions override which is neither ltr nor rtl is a problem of the Config
+func TestEngineDirectionInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"frala.json": `{"DefaultLanguage": "en", "Directions": {"he": "up"}}`})
+
+	_, newErr := New(Options{ConfigFile: filepath.Join(dir, "frala.json")})
+
+	if newErr == nil || !strings.Contains(newErr.Error(), "Directions of he: up is not ltr or rtl") {
+		t.Errorf("New = %v, want a problem with the Directions of he", newErr)
+	}
+}
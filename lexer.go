@@ -0,0 +1,236 @@
+// This file contains functionality for tokenizing content into Frala syntax and text
+
+package frala
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenType is the type of a token produced by the lexer
+type tokenType int
+
+const (
+	tokenEOF    tokenType = iota // End of the content
+	tokenText                    // Content outside of Frala syntax
+	tokenOpen                    // Opening {{ of Frala syntax
+	tokenClose                   // Closing }} of Frala syntax
+	tokenWord                    // Unquoted word, such as an attribute name or bare value
+	tokenEquals                  // = between an attribute name and value
+	tokenString                  // Quoted value
+)
+
+// token is a single lexical item of the content
+type token struct {
+	typ    tokenType // Type of the token
+	val    string    // Value of the token, with quotes and escapes removed for tokenString
+	offset int       // Byte offset where the token starts
+	end    int       // Byte offset where the token ends
+}
+
+// lexer tokenizes content into text and Frala syntax
+type lexer struct {
+	name       string       // Name of the file being tokenized
+	content    string       // Content being tokenized
+	offset     int          // Current byte offset in content
+	inTag      bool         // Whether we are currently inside {{ and }}
+	lineStarts []int        // Byte offsets of the start of each line, used for positions
+	tokens     []token      // Tokens produced so far
+	openQuote  int          // Byte offset of a quoted value the content ended inside of, or 0 if none did
+	warnings   []ParseError // Problems which did not stop the content being tokenized, such as unterminated Frala syntax
+}
+
+// newLexer creates a lexer for the content provided
+func newLexer(name, content string) *lexer {
+	l := &lexer{name: name, content: content, lineStarts: []int{0}}
+
+	for index := 0; index < len(content); index++ { // For each byte of the content
+		if content[index] == '\n' { // If this is the end of a line
+			l.lineStarts = append(l.lineStarts, index+1)
+		}
+	}
+
+	return l
+}
+
+// position converts a byte offset into a Position
+func (l *lexer) position(offset int) Position {
+	line := sort.Search(len(l.lineStarts), func(i int) bool { return l.lineStarts[i] > offset }) // Find the first line starting after offset
+	lineStart := l.lineStarts[line-1]
+
+	return Position{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(l.content[lineStart:offset]) + 1,
+	}
+}
+
+// errorf creates a ParseError of ErrorSyntax at the byte offset provided
+func (l *lexer) errorf(offset int, message string) error {
+	parseErr := l.newParseError(offset, message)
+	return &parseErr
+}
+
+// newParseError creates a ParseError of ErrorSyntax at the byte offset provided
+func (l *lexer) newParseError(offset int, message string) ParseError {
+	pos := l.position(offset)
+	return ParseError{File: l.name, Line: pos.Line, Column: pos.Column, Kind: ErrorSyntax, Message: message}
+}
+
+// emit adds a token spanning start to the current offset
+func (l *lexer) emit(typ tokenType, start int, val string) {
+	l.tokens = append(l.tokens, token{typ: typ, val: val, offset: start, end: l.offset})
+}
+
+// run tokenizes all of the content, returning the tokens or the first syntax ParseError
+// Frala syntax the content ends inside of is kept as text, with a warning, so a stray {{ doesn't fail the whole file.
+func (l *lexer) run() ([]token, error) {
+	for l.offset < len(l.content) {
+		var lexErr error
+
+		if l.inTag { // Inside Frala syntax
+			lexErr = l.lexTag()
+		} else { // Outside Frala syntax
+			l.lexText()
+		}
+
+		if lexErr != nil {
+			return nil, lexErr
+		}
+	}
+
+	if l.inTag { // If the content ended without closing the Frala syntax
+		l.keepUnterminated()
+	}
+
+	l.emit(tokenEOF, l.offset, "")
+	return l.tokens, nil
+}
+
+// keepUnterminated turns the Frala syntax the content ended inside of back into text, warning about it
+func (l *lexer) keepUnterminated() {
+	lastOpen := len(l.tokens) - 1
+
+	for l.tokens[lastOpen].typ != tokenOpen { // Find the unterminated {{, which always precedes the tokens of its Frala syntax
+		lastOpen--
+	}
+
+	if l.openQuote != 0 { // If the content ended inside a quoted value, report the quote rather than the {{
+		l.warnings = append(l.warnings, l.newParseError(l.openQuote, "Unterminated quoted value, so the Frala syntax is kept as text"))
+	} else {
+		l.warnings = append(l.warnings, l.newParseError(l.tokens[lastOpen].offset, "Unterminated Frala syntax, expected }}, so it is kept as text"))
+	}
+
+	start := l.tokens[lastOpen].offset
+	l.tokens = l.tokens[:lastOpen]
+
+	if len(l.tokens) != 0 && l.tokens[len(l.tokens)-1].typ == tokenText { // Join the text before the {{, so the text is a single token
+		start = l.tokens[len(l.tokens)-1].offset
+		l.tokens = l.tokens[:len(l.tokens)-1]
+	}
+
+	l.emit(tokenText, start, l.content[start:])
+	l.inTag = false
+}
+
+// lexText tokenizes content up to and including the next {{
+func (l *lexer) lexText() {
+	start := l.offset
+	openIndex := strings.Index(l.content[l.offset:], "{{")
+
+	if openIndex == -1 { // No more Frala syntax
+		l.offset = len(l.content)
+		l.emit(tokenText, start, l.content[start:])
+		return
+	}
+
+	if openIndex > 0 { // If there is text before the Frala syntax
+		l.offset += openIndex
+		l.emit(tokenText, start, l.content[start:l.offset])
+	}
+
+	openStart := l.offset
+	l.offset += 2
+	l.emit(tokenOpen, openStart, "{{")
+	l.inTag = true
+}
+
+// lexTag tokenizes a single item inside Frala syntax
+func (l *lexer) lexTag() error {
+	r, width := utf8.DecodeRuneInString(l.content[l.offset:])
+
+	switch {
+	case isSpace(r): // Whitespace, including newlines, separates items
+		l.offset += width
+	case strings.HasPrefix(l.content[l.offset:], "}}"): // End of Frala syntax
+		start := l.offset
+		l.offset += 2
+		l.emit(tokenClose, start, "}}")
+		l.inTag = false
+	case r == '=':
+		start := l.offset
+		l.offset += width
+		l.emit(tokenEquals, start, "=")
+	case r == '"' || r == '\'':
+		return l.lexString(r)
+	case strings.HasPrefix(l.content[l.offset:], "{{"): // Frala syntax cannot be nested
+		return l.errorf(l.offset, "Unexpected {{ inside Frala syntax")
+	default:
+		l.lexWord()
+	}
+
+	return nil
+}
+
+// lexString tokenizes a quoted value, supporting \ escapes of the quote and backslash
+func (l *lexer) lexString(quote rune) error {
+	start := l.offset
+	l.offset++ // Skip the opening quote
+
+	var value strings.Builder
+
+	for l.offset < len(l.content) {
+		c := l.content[l.offset]
+
+		if c == '\\' && l.offset+1 < len(l.content) && (rune(l.content[l.offset+1]) == quote || l.content[l.offset+1] == '\\') { // Escaped quote or backslash
+			value.WriteByte(l.content[l.offset+1])
+			l.offset += 2
+			continue
+		}
+
+		l.offset++
+
+		if rune(c) == quote { // Closing quote
+			l.emit(tokenString, start, value.String())
+			return nil
+		}
+
+		value.WriteByte(c)
+	}
+
+	l.openQuote = start // The content ended inside the quoted value, which is kept as text along with its Frala syntax
+	return nil
+}
+
+// lexWord tokenizes an unquoted word, ending at whitespace, =, a quote or }}
+func (l *lexer) lexWord() {
+	start := l.offset
+
+	for l.offset < len(l.content) {
+		r, width := utf8.DecodeRuneInString(l.content[l.offset:])
+
+		if isSpace(r) || r == '=' || r == '"' || r == '\'' || strings.HasPrefix(l.content[l.offset:], "}}") {
+			break
+		}
+
+		l.offset += width
+	}
+
+	l.emit(tokenWord, start, l.content[start:l.offset])
+}
+
+// isSpace returns whether the rune is whitespace separating items in Frala syntax
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
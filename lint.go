@@ -0,0 +1,324 @@
+// This file contains linting, reporting problems in templates and Terms without rendering them
+
+package frala
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// builtinTerms are the terms provided by Frala rather than the Terms of the Config
+var builtinTerms = map[string]bool{
+	"frala.CurrentLanguage": true,
+	"frala.DefaultLanguage": true,
+	"frala.Direction":       true,
+	"frala.Languages":       true,
+}
+
+// Lint parses each template and the Fragments it imports with the default Engine without rendering them, reporting problems with file:line:column positions
+func Lint(files []string) ([]ParseError, error) {
+	return Default().Lint(files)
+}
+
+// linter walks the Documents of templates and their Fragments, without rendering them
+type linter struct {
+	engine      *Engine
+	diagnostics []ParseError               // Problems found so far
+	includes    []string                   // Files currently being linted, for include cycle detection
+	linted      map[string]bool            // Files already linted, so the problems of a Fragment are only reported once
+	weighted    bool                       // Whether Fragments are linted each time they are imported, so each usage of a Term is counted for every template including it
+	usages      map[string][]Context       // Every Context using each Term, in the order they were linted
+	languages   map[string]map[string]bool // Languages each Term is used in, with an empty language for every language of the Config
+}
+
+// Lint parses each template and the Fragments it imports without rendering them, reporting problems with file:line:column positions
+// Along with the problems reported while parsing, this reports terms which are not defined in the Config, Terms of the Config which none of the templates use,
+// Terms without a value for a language of the Config, and attributes which have no effect. Terms are not added to the Config and no Fallbacks are recorded.
+// Returns an error if a template could not be read.
+func (e *Engine) Lint(files []string) ([]ParseError, error) {
+	l := newLinter(e)
+
+	if lintErr := l.lintFiles(files); lintErr != nil {
+		return nil, lintErr
+	}
+
+	l.lintTerms()
+	return l.diagnostics, nil
+}
+
+// newLinter creates a linter for the Engine
+func newLinter(e *Engine) *linter {
+	return &linter{
+		engine:    e,
+		linted:    make(map[string]bool),
+		usages:    make(map[string][]Context),
+		languages: make(map[string]map[string]bool),
+	}
+}
+
+// lintFiles lints each template, returning an error if one could not be read
+func (l *linter) lintFiles(files []string) error {
+	for _, file := range files { // For each template
+		if lintErr := l.lintFile(file); lintErr != nil {
+			return lintErr
+		}
+	}
+
+	return nil
+}
+
+// lintFile lints the Document of a file, unless it has already been linted
+func (l *linter) lintFile(file string) error {
+	includePath, pathErr := l.engine.includePath(file)
+
+	if pathErr == nil && l.linted[includePath] && !l.weighted { // If the file has already been linted, such as a Fragment imported by several templates
+		return nil
+	}
+
+	document, loadErr := l.engine.loadDocument(file)
+
+	if parseErr, isParseErr := loadErr.(*ParseError); isParseErr { // If the Frala syntax is malformed
+		l.diagnostics = append(l.diagnostics, *parseErr)
+		return nil
+	} else if loadErr != nil { // If the file could not be read
+		return loadErr
+	}
+
+	if pathErr == nil { // Track the file as being linted, for include cycle detection
+		l.linted[includePath] = true
+		l.includes = append(l.includes, includePath)
+		defer func() { l.includes = l.includes[:len(l.includes)-1] }()
+	}
+
+	l.diagnostics = append(l.diagnostics, document.Warnings...)
+
+	for _, node := range document.Nodes { // For each Node which is Frala syntax
+		if node.Type == TagNode {
+			context := NewContext(document.Name, node)
+			l.lintContext(&context)
+		}
+	}
+
+	return nil
+}
+
+// lintContext lints a Frala context, following it if it is a Fragment
+func (l *linter) lintContext(c *Context) {
+	if c.Source == "" { // No Source set
+		l.diagnostics = append(l.diagnostics, c.newParseError(ErrorMissingSource, "Source for this Frala syntax not specified."))
+	} else if c.Type == "" { // No Type set
+		l.diagnostics = append(l.diagnostics, c.newParseError(ErrorMissingType, "Type for this Frala syntax not specified."))
+	} else if c.Type == "fragment" { // If this is a Fragment
+		l.lintAttributes(c, "a fragment", func(string) bool { return false })
+		l.lintFragment(c)
+	} else if c.Type == "term" { // If this is a term
+		l.lintTerm(c)
+	} else {
+		l.diagnostics = append(l.diagnostics, c.newParseError(ErrorInvalidType, c.Type+" is not a valid type."))
+	}
+}
+
+// lintFragment lints the file of a Fragment, ensuring it can be read and doesn't create an include cycle
+func (l *linter) lintFragment(c *Context) {
+	fragmentFile, includeErrs := l.engine.includeFragment(c, l.includes)
+
+	if len(includeErrs) != 0 { // If the Fragment can't be imported
+		l.diagnostics = append(l.diagnostics, includeErrs...)
+	} else if lintErr := l.lintFile(fragmentFile); lintErr != nil { // If the fragment file does not exist
+		l.diagnostics = append(l.diagnostics, c.newParseError(ErrorFragmentRead, lintErr.Error()))
+	}
+}
+
+// lintTerm lints a term, recording the languages it is used in
+func (l *linter) lintTerm(c *Context) {
+	language := "" // Every language of the Config, unless the term has a lang
+
+	if c.Lang != "" { // If the term is always in one language
+		if _, tagErr := ParseTag(c.Lang); tagErr != nil { // If the language isn't a valid tag
+			l.diagnostics = append(l.diagnostics, c.newParseError(ErrorInvalidLanguage, tagErr.Error()))
+			return
+		}
+
+		language = CanonicalLanguage(c.Lang)
+	}
+
+	if builtinTerms[c.Source] { // If Frala provides the term, none of its other attributes are used
+		l.lintAttributes(c, c.Source, func(string) bool { return false })
+		return
+	}
+
+	if _, used := l.usages[c.Source]; !used { // If this is the first use of the Term
+		l.languages[c.Source] = make(map[string]bool)
+	}
+
+	l.usages[c.Source] = append(l.usages[c.Source], *c)
+	l.languages[c.Source][language] = true
+
+	e := l.engine
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	term, defined := e.Config.Terms[c.Source]
+
+	if !defined { // If the Term would be added to the Config untranslated when parsing
+		l.diagnostics = append(l.diagnostics, c.newParseError(ErrorUndefinedTerm, "Term "+c.Source+" is not defined"))
+		return
+	}
+
+	l.lintAttributes(c, "Term "+c.Source, func(name string) bool {
+		for _, value := range term { // For each value, in any language
+			if valueUsesPlaceholder(value, name) || (name == "count" && value.IsPlural()) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// lintAttributes reports each attribute of a Context which has no effect: any besides type, src, the lang of a term, and those accepted by used
+func (l *linter) lintAttributes(c *Context, name string, used func(string) bool) {
+	for _, attribute := range c.Node.Attributes { // For each attribute, in source order
+		if attribute.Name == "type" || attribute.Name == "src" || (attribute.Name == "lang" && c.Type == "term" && !builtinTerms[c.Source]) || used(attribute.Name) {
+			continue
+		}
+
+		diagnostic := c.newParseError(ErrorUnknownAttribute, "Unknown attribute "+attribute.Name+" for "+name)
+		diagnostic.Line, diagnostic.Column = attribute.Pos.Line, attribute.Pos.Column // Report the attribute itself, rather than the start of the Frala syntax
+		l.diagnostics = append(l.diagnostics, diagnostic)
+	}
+}
+
+// lintTerms reports Terms without a value for a language they are used in, and Terms which are not used
+func (l *linter) lintTerms() {
+	e := l.engine
+	positions := e.termPositions()
+	configFile := e.configFile
+
+	if configFile == "" { // If the Config was provided rather than read from a file
+		configFile = "Config"
+	}
+
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	termNames := make([]string, 0, len(e.Config.Terms))
+
+	for termName := range e.Config.Terms {
+		termNames = append(termNames, termName)
+	}
+
+	sort.Strings(termNames)
+
+	for _, termName := range termNames { // For each Term, in order
+		usages, used := l.usages[termName]
+
+		if !used { // If no template uses the Term
+			l.diagnostics = append(l.diagnostics, ParseError{
+				File:    configFile,
+				Line:    positions[termName].Line,
+				Column:  positions[termName].Column,
+				Kind:    ErrorUnusedTerm,
+				Message: "Term " + termName + " is not used by any template",
+			})
+
+			continue
+		}
+
+		for _, language := range l.termLanguages(termName) { // For each language the Term is used in, ensure it has a value
+			if _, translated := e.Config.Terms[termName][language]; translated {
+				continue
+			}
+
+			message := "Term " + termName + " is not translated into " + language + " or any of its fallbacks"
+
+			if fallback, hasFallback := e.Config.termFallback(e.Config.Terms[termName], language); hasFallback {
+				message = "Term " + termName + " is not translated into " + language + ", falling back to " + fallback
+			}
+
+			l.diagnostics = append(l.diagnostics, usages[0].newParseError(ErrorMissingTranslation, message)) // Report the first use of the Term
+		}
+	}
+}
+
+// termLanguages gets the languages a Term is used in, in order
+func (l *linter) termLanguages(termName string) []string {
+	var languages []string
+
+	for language := range l.languages[termName] {
+		if language == "" { // If the Term is used in every language of the Config
+			languages = append(languages, l.engine.Config.availableLanguages()...) // The caller holds the lock of the Config
+		} else {
+			languages = append(languages, language)
+		}
+	}
+
+	sort.Strings(languages)
+	unique := languages[:0]
+
+	for index, language := range languages { // Remove any language used both explicitly and as a language of the Config
+		if index == 0 || language != languages[index-1] {
+			unique = append(unique, language)
+		}
+	}
+
+	return unique
+}
+
+// termPositions gets the position of each Term in the config file, so problems with Terms can be reported where they are defined
+func (e *Engine) termPositions() map[string]Position {
+	positions := make(map[string]Position)
+
+	if e.configFile == "" { // If the Config was not read from a file
+		return positions
+	}
+
+	content, readErr := ioutil.ReadFile(e.configFile)
+
+	if readErr != nil {
+		return positions
+	}
+
+	l := newLexer(e.configFile, string(content)) // Only used to convert offsets into positions
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	if token, tokenErr := decoder.Token(); tokenErr != nil || token != json.Delim('{') { // If the config is not an object
+		return positions
+	}
+
+	for decoder.More() { // For each setting of the config, until the Terms
+		key, keyErr := decoder.Token()
+		var skipped json.RawMessage
+
+		if setting, _ := key.(string); keyErr != nil || !strings.EqualFold(setting, "Terms") { // If this is another setting, skip its value
+			if keyErr != nil || decoder.Decode(&skipped) != nil {
+				return positions
+			}
+
+			continue
+		}
+
+		if token, tokenErr := decoder.Token(); tokenErr != nil || token != json.Delim('{') { // If the Terms are not an object
+			return positions
+		}
+
+		for decoder.More() { // For each Term
+			offset := int(decoder.InputOffset()) // The end of the previous token, followed by any comma and whitespace
+			termKey, termErr := decoder.Token()
+
+			if termErr != nil || decoder.Decode(&skipped) != nil {
+				return positions
+			}
+
+			termName, _ := termKey.(string)
+			positions[termName] = l.position(offset + bytes.IndexByte(content[offset:], '"'))
+		}
+
+		break
+	}
+
+	return positions
+}
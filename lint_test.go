@@ -0,0 +1,134 @@
+// This file contains the tests of linting templates and Terms without rendering them
+
+package frala
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLint ensures each kind of problem is reported once, at the position of the Frala syntax or attribute causing it
+func TestLint(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {
+		"hello": {"en": "Hello", "fi": "Hei"},
+		"bye": {"en": "Bye"},
+		"thanks": {"fi": "Kiitos"},
+		"greet": {"en": "Hello {name}", "fi": "Hei {name}"},
+		"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}, "fi": {"one": "{count} omena", "other": "{count} omenaa"}},
+		"unused": {"en": "Unused", "fi": "Käyttämätön"}
+	}}`, map[string]string{
+		"header.html": strings.Join([]string{
+			`{{ type="term" src="hello" }}`,
+			`{{ type="fragment" src="nope.html" }}`,
+			`{{ type="term" src="thanks" lang="fi" }}`,
+			`{{ type="term" src="apples" count="2" }}`,
+		}, "\n"),
+		"index.html": strings.Join([]string{
+			`{{ type="term" src="hello" }}`,
+			`{{ type="term" src="missing" }}`,
+			`{{ type="term" src="bye" }}`,
+			`{{ type="term" src="greet" name="Ada" colour="red" }}`,
+			`{{ type="fragment" src="header.html" }}`,
+			`{{ type="widget" src="x" }}`,
+			`{{ src="hello" }}`,
+			`{{ type="term" src="hello" lang="!!" }}`,
+			`{{ type="term" src="frala.Direction" lang="fi" }}`,
+			`{{ type="term" }}`,
+			`{{ type="fragment" src="index.html" x="1" }}`,
+			`{{ type="term" src="thanks" }}`,
+			`{{ type="term" src="bad`,
+		}, "\n"),
+		"about.html": `{{ type="fragment" src="header.html" }}`, // Problems of the header are only reported once
+	})
+
+	want := []struct {
+		file   string
+		line   int
+		column int
+		kind   ErrorKind
+	}{
+		{"index.html", 13, 20, ErrorSyntax}, // Warnings of the lexer come first
+		{"index.html", 2, 1, ErrorUndefinedTerm},
+		{"index.html", 4, 39, ErrorUnknownAttribute}, // At the attribute
+		{"header.html", 2, 1, ErrorFragmentRead},
+		{"index.html", 6, 1, ErrorInvalidType},
+		{"index.html", 7, 1, ErrorMissingType},
+		{"index.html", 8, 1, ErrorInvalidLanguage},
+		{"index.html", 9, 38, ErrorUnknownAttribute}, // Built-in terms have no lang
+		{"index.html", 10, 1, ErrorMissingSource},
+		{"index.html", 11, 37, ErrorUnknownAttribute},
+		{"index.html", 11, 1, ErrorSelfImport},
+		{"index.html", 3, 1, ErrorMissingTranslation},  // bye in fi, at its first use
+		{"header.html", 3, 1, ErrorMissingTranslation}, // thanks in en, at its first use
+		{"frala.json", 7, 3, ErrorUnusedTerm},
+	}
+
+	diagnostics, lintErr := engine.Lint([]string{filepath.Join(dir, "index.html"), filepath.Join(dir, "about.html")})
+
+	if lintErr != nil {
+		t.Fatal(lintErr)
+	}
+
+	if len(diagnostics) != len(want) {
+		t.Errorf("Lint = %d problems, want %d", len(diagnostics), len(want))
+	}
+
+	for index, diagnostic := range diagnostics {
+		if index >= len(want) {
+			t.Errorf("Unexpected %v", diagnostic)
+			continue
+		}
+
+		expected := want[index]
+		file, _ := filepath.Rel(dir, diagnostic.File)
+
+		if file != expected.file || diagnostic.Line != expected.line || diagnostic.Column != expected.column || diagnostic.Kind != expected.kind {
+			t.Errorf("Problem %d = %s %d:%d %v, want %s %d:%d %v", index, file, diagnostic.Line, diagnostic.Column, diagnostic.Kind, expected.file, expected.line, expected.column, expected.kind)
+		}
+	}
+}
+
+// TestLintMessages ensures untranslated Terms report whether they fall back, and Lint doesn't add Terms to the Config
+func TestLintMessages(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"bye": {"en": "Bye"}, "thanks": {"fi": "Kiitos"}}}`, map[string]string{
+		"index.html": `{{ type="term" src="bye" }} {{ type="term" src="thanks" }} {{ type="term" src="missing" }}`,
+	})
+
+	diagnostics, lintErr := engine.Lint([]string{filepath.Join(dir, "index.html")})
+
+	if lintErr != nil {
+		t.Fatal(lintErr)
+	}
+
+	want := map[string]bool{
+		"Term missing is not defined":                                   true,
+		"Term bye is not translated into fi, falling back to en":        true,
+		"Term thanks is not translated into en or any of its fallbacks": true,
+	}
+
+	for _, diagnostic := range diagnostics {
+		if !want[diagnostic.Message] {
+			t.Errorf("Unexpected %q", diagnostic.Message)
+		}
+
+		delete(want, diagnostic.Message)
+	}
+
+	for message := range want {
+		t.Errorf("Missing %q", message)
+	}
+
+	if _, added := engine.Config.Terms["missing"]; added {
+		t.Error("Lint added the undefined Term to the Config")
+	}
+}
+
+// TestLintUnreadable ensures a template which can't be read is an error rather than a problem
+func TestLintUnreadable(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en"}`, nil)
+
+	if diagnostics, lintErr := engine.Lint([]string{filepath.Join(dir, "none.html")}); lintErr == nil {
+		t.Errorf("Lint = %v, want an error", diagnostics)
+	}
+}
@@ -0,0 +1,493 @@
+// This file contains functionality for parsing and formatting ICU MessageFormat values of Terms
+
+package frala
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// The types of MessageFormat arguments
+const (
+	argumentSimple        = ""              // {name}
+	argumentNumber        = "number"        // {name, number} or {name, number, integer|percent}
+	argumentDate          = "date"          // {name, date} or {name, date, short|medium|long|full}
+	argumentTime          = "time"          // {name, time} or {name, time, short|medium|long|full}
+	argumentPlural        = "plural"        // {name, plural, offset:1 =0 {...} one {...} other {...}}
+	argumentSelectOrdinal = "selectordinal" // {name, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}
+	argumentSelect        = "select"        // {name, select, male {...} female {...} other {...}}
+)
+
+// Message is a parsed ICU MessageFormat value, such as "{count, plural, one {# file} other {# files}}"
+// A value without any MessageFormat syntax is a Message of only text.
+type Message struct {
+	parts []messagePart // Parts of the Message, in source order
+}
+
+// messagePart is literal text, a # of a plural, or an argument of a Message
+type messagePart struct {
+	text     string          // Literal text, if this part is neither an argument nor a #
+	pound    bool            // Whether this part is a #, replaced with the number of the innermost plural
+	argument string          // Name of the argument, if this part is an argument
+	kind     string          // Type of the argument, such as argumentPlural
+	style    string          // Style of a number, date or time argument
+	offset   float64         // Offset of a plural argument, subtracted from its number before choosing the category
+	options  []messageOption // Options of a plural, selectordinal or select argument
+	source   string          // Source of the argument, written as-is if the argument has no value
+}
+
+// messageOption is an option of a plural, selectordinal or select argument, such as one {# file}
+type messageOption struct {
+	selector string   // Selector of the option, such as one, =0 or female
+	message  *Message // Message used when the option is selected
+}
+
+// messageParser parses a MessageFormat value
+type messageParser struct {
+	value  string // Value being parsed
+	offset int    // Byte offset of the next character
+}
+
+// ParseMessage parses an ICU MessageFormat value, returning a *MessageError if its syntax is invalid
+// An apostrophe before {, } or # quotes literal text until the next apostrophe, and two apostrophes are a single apostrophe.
+func ParseMessage(value string) (*Message, error) {
+	p := &messageParser{value: value}
+	return p.parseMessage(false, false)
+}
+
+// errorf creates a MessageError at the current offset
+func (p *messageParser) errorf(message string) error {
+	return &MessageError{Offset: p.offset, Message: message}
+}
+
+// peek gets the next character, or 0 at the end of the value
+func (p *messageParser) peek() byte {
+	if p.offset < len(p.value) {
+		return p.value[p.offset]
+	}
+
+	return 0
+}
+
+// skipSpace skips any whitespace
+func (p *messageParser) skipSpace() {
+	for p.offset < len(p.value) && strings.IndexByte(" \t\r\n", p.value[p.offset]) != -1 {
+		p.offset++
+	}
+}
+
+// readWhile reads characters while valid returns true
+func (p *messageParser) readWhile(valid func(c byte) bool) string {
+	start := p.offset
+
+	for p.offset < len(p.value) && valid(p.value[p.offset]) {
+		p.offset++
+	}
+
+	return p.value[start:p.offset]
+}
+
+// parseMessage parses text and arguments until the end of the value, or the } ending a nested message
+func (p *messageParser) parseMessage(inPlural, nested bool) (*Message, error) {
+	message := &Message{}
+	var text strings.Builder
+
+	flush := func() { // Add any pending literal text as a part
+		if text.Len() != 0 {
+			message.parts = append(message.parts, messagePart{text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.offset < len(p.value) {
+		switch c := p.value[p.offset]; {
+		case c == '\'':
+			p.offset++
+			p.parseQuote(&text, inPlural)
+		case c == '{':
+			flush()
+			part, argumentErr := p.parseArgument(inPlural)
+
+			if argumentErr != nil {
+				return nil, argumentErr
+			}
+
+			message.parts = append(message.parts, part)
+		case c == '}':
+			if !nested { // If there is no message for this } to end
+				return nil, p.errorf("Unexpected }, use '}' for a literal }")
+			}
+
+			flush()
+			return message, nil // Leave the } for the option to consume
+		case c == '#' && inPlural:
+			flush()
+			message.parts = append(message.parts, messagePart{pound: true})
+			p.offset++
+		default:
+			text.WriteByte(c)
+			p.offset++
+		}
+	}
+
+	if nested { // If the value ended before the nested message
+		return nil, p.errorf("Expected } to end the option")
+	}
+
+	flush()
+	return message, nil
+}
+
+// parseQuote parses the text after an apostrophe, writing any literal text
+func (p *messageParser) parseQuote(text *strings.Builder, inPlural bool) {
+	next := p.peek()
+
+	if next == '\'' { // Two apostrophes are a literal apostrophe
+		text.WriteByte('\'')
+		p.offset++
+		return
+	} else if next == 0 || (next != '{' && next != '}' && next != '|' && (next != '#' || !inPlural)) { // Any other apostrophe is literal, such as in don't
+		text.WriteByte('\'')
+		return
+	}
+
+	for p.offset < len(p.value) { // Quoted text continues until the next apostrophe
+		c := p.value[p.offset]
+		p.offset++
+
+		if c != '\'' {
+			text.WriteByte(c)
+		} else if p.peek() == '\'' { // An escaped apostrophe inside quoted text
+			text.WriteByte('\'')
+			p.offset++
+		} else { // The end of the quoted text
+			return
+		}
+	}
+}
+
+// parseArgument parses an argument, starting at its {
+func (p *messageParser) parseArgument(inPlural bool) (messagePart, error) {
+	start := p.offset
+	p.offset++
+	p.skipSpace()
+
+	part := messagePart{argument: p.readWhile(isArgumentChar)}
+
+	if !isArgumentName(part.argument) {
+		return part, p.errorf("Expected argument name")
+	}
+
+	p.skipSpace()
+
+	if p.peek() == '}' { // If this is a simple argument
+		p.offset++
+		part.source = p.value[start:p.offset]
+		return part, nil
+	} else if p.peek() != ',' {
+		return part, p.errorf("Expected , or } after argument " + part.argument)
+	}
+
+	p.offset++
+	p.skipSpace()
+	part.kind = p.readWhile(func(c byte) bool { return c >= 'a' && c <= 'z' })
+	p.skipSpace()
+
+	var argumentErr error
+
+	switch part.kind {
+	case argumentNumber, argumentDate, argumentTime:
+		argumentErr = p.parseStyle(&part)
+	case argumentPlural, argumentSelectOrdinal, argumentSelect:
+		argumentErr = p.parseOptions(&part, inPlural || part.kind != argumentSelect)
+	default:
+		argumentErr = p.errorf("Unknown type " + part.kind + " of argument " + part.argument + ", use number, date, time, plural, selectordinal or select")
+	}
+
+	if argumentErr == nil && part.kind != argumentNumber && part.kind != argumentDate && part.kind != argumentTime && part.option(PluralOther).selector != PluralOther { // Every plural and select must have an other option
+		p.offset = start
+		argumentErr = p.errorf("Argument " + part.argument + " has no other option")
+	}
+
+	part.source = p.value[start:p.offset]
+	return part, argumentErr
+}
+
+// parseStyle parses the optional style of a number, date or time argument, through to its }
+func (p *messageParser) parseStyle(part *messagePart) error {
+	if p.peek() == ',' { // If a style is provided
+		p.offset++
+		part.style = strings.TrimSpace(p.readWhile(func(c byte) bool { return c != '}' && c != '{' }))
+	}
+
+	if p.peek() != '}' {
+		return p.errorf("Expected } after argument " + part.argument)
+	}
+
+	p.offset++
+
+	validStyles := "short,medium,long,full"
+
+	if part.kind == argumentNumber {
+		validStyles = "integer,percent"
+	}
+
+	if part.style != "" && !strings.Contains(","+validStyles+",", ","+part.style+",") {
+		return p.errorf("Unknown " + part.kind + " style " + part.style + ", use " + strings.Replace(validStyles, ",", ", ", -1))
+	}
+
+	return nil
+}
+
+// parseOptions parses the optional offset and the options of a plural, selectordinal or select argument, through to its }
+func (p *messageParser) parseOptions(part *messagePart, inPlural bool) error {
+	if p.peek() != ',' {
+		return p.errorf("Expected , after " + part.kind)
+	}
+
+	p.offset++
+	p.skipSpace()
+
+	if part.kind != argumentSelect && strings.HasPrefix(p.value[p.offset:], "offset:") { // If the plural has an offset
+		p.offset += len("offset:")
+		p.skipSpace()
+
+		offset, offsetErr := strconv.ParseFloat(p.readWhile(isArgumentChar), 64)
+
+		if offsetErr != nil {
+			return p.errorf("Expected number after offset:")
+		}
+
+		part.offset = offset
+	}
+
+	seen := make(map[string]bool)
+
+	for {
+		p.skipSpace()
+
+		if p.peek() == '}' { // The end of the argument
+			p.offset++
+			return nil
+		} else if p.peek() == 0 {
+			return p.errorf("Expected } after the options of argument " + part.argument)
+		}
+
+		selector := p.readWhile(func(c byte) bool { return strings.IndexByte(" \t\r\n{}", c) == -1 })
+
+		if selectorErr := p.checkSelector(part.kind, selector); selectorErr != nil {
+			return selectorErr
+		} else if seen[selector] {
+			return p.errorf("Duplicate option " + selector + " of argument " + part.argument)
+		}
+
+		seen[selector] = true
+		p.skipSpace()
+
+		if p.peek() != '{' {
+			return p.errorf("Expected { after option " + selector)
+		}
+
+		p.offset++
+		message, messageErr := p.parseMessage(inPlural, true)
+
+		if messageErr != nil {
+			return messageErr
+		}
+
+		p.offset++ // Consume the } of the option
+		part.options = append(part.options, messageOption{selector: selector, message: message})
+	}
+}
+
+// checkSelector ensures a selector is valid for the type of argument: a plural category or =number for plurals, or a name for selects
+func (p *messageParser) checkSelector(kind, selector string) error {
+	if selector == "" {
+		return p.errorf("Expected option")
+	}
+
+	if kind == argumentSelect {
+		if !isArgumentName(selector) {
+			return p.errorf(selector + " is not a valid option")
+		}
+
+		return nil
+	}
+
+	if strings.HasPrefix(selector, "=") { // If this is an exact match
+		if _, numberErr := strconv.ParseFloat(selector[1:], 64); numberErr != nil {
+			return p.errorf(selector + " is not a valid option, = must be followed by a number")
+		}
+
+		return nil
+	}
+
+	switch selector {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return nil
+	}
+
+	return p.errorf(selector + " is not a plural category. Use zero, one, two, few, many, other or =number")
+}
+
+// isArgumentChar returns whether the character can be part of an argument name or number
+func isArgumentChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '.' || c == '-'
+}
+
+// isArgumentName returns whether the name is a valid argument name: a number, or a letter or underscore followed by letters, digits, underscores, dots or dashes
+func isArgumentName(name string) bool {
+	if name == "" {
+		return false
+	} else if isDigits(name) {
+		return true
+	}
+
+	for index := 0; index < len(name); index++ { // For each character
+		c := name[index]
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+
+		if !isArgumentChar(c) || (index == 0 && !isLetter) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// option gets the option of a plural, selectordinal or select argument for a selector, or its other option if it has none
+func (part messagePart) option(selector string) messageOption {
+	var other messageOption
+
+	for _, option := range part.options { // For each option
+		if option.selector == selector {
+			return option
+		} else if option.selector == PluralOther {
+			other = option
+		}
+	}
+
+	return other
+}
+
+// Arguments gets the unique names of the arguments of the Message, including those nested in options, in the order they first appear
+func (m *Message) Arguments() []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var collect func(*Message)
+	collect = func(message *Message) {
+		for _, part := range message.parts { // For each part
+			if part.argument != "" && !seen[part.argument] {
+				seen[part.argument] = true
+				names = append(names, part.argument)
+			}
+
+			for _, option := range part.options { // For each option of a plural or select
+				collect(option.message)
+			}
+		}
+	}
+
+	collect(m)
+	return names
+}
+
+// messageFormatter is the state of formatting a Message
+type messageFormatter struct {
+	language  string            // Language used for plural rules and number and date formats
+	arguments map[string]string // Values of the arguments
+	missing   []string          // Names of the arguments without a value
+	err       error             // First argument with an invalid value
+}
+
+// Format formats the Message in a language with the arguments provided
+// Returns the content, the names of any arguments without a value (which are written as-is), and an error for the first argument with an invalid value, such as a plural argument which isn't a number.
+func (m *Message) Format(language string, arguments map[string]string) (string, []string, error) {
+	var content strings.Builder
+	f := &messageFormatter{language: language, arguments: arguments}
+	f.format(&content, m, "")
+
+	return content.String(), f.missing, f.err
+}
+
+// format writes each part of a Message, with pound being the formatted number of the innermost plural
+func (f *messageFormatter) format(content *strings.Builder, m *Message, pound string) {
+	for _, part := range m.parts { // For each part
+		if part.pound {
+			content.WriteString(pound)
+		} else if part.argument == "" { // Literal text
+			content.WriteString(part.text)
+		} else {
+			f.formatArgument(content, part, pound)
+		}
+	}
+}
+
+// formatArgument writes the value of an argument, formatted for its type
+func (f *messageFormatter) formatArgument(content *strings.Builder, part messagePart, pound string) {
+	value, exists := f.arguments[part.argument]
+
+	if !exists { // If there is no value for the argument, leave it as-is
+		f.missing = append(f.missing, part.argument)
+		content.WriteString(part.source)
+		return
+	}
+
+	var formatErr error
+
+	switch part.kind {
+	case argumentSimple:
+		content.WriteString(value)
+	case argumentNumber:
+		value, formatErr = formatNumber(f.language, value, part.style)
+		content.WriteString(value)
+	case argumentDate, argumentTime:
+		value, formatErr = formatDateTime(f.language, value, part.kind, part.style)
+		content.WriteString(value)
+	case argumentSelect:
+		f.format(content, part.option(value).message, pound)
+	default: // A plural or selectordinal
+		formatErr = f.formatPlural(content, part, value)
+	}
+
+	if formatErr != nil && f.err == nil { // Keep the first error
+		f.err = &ArgumentError{Argument: part.argument, Value: value, Err: formatErr}
+	}
+}
+
+// formatPlural writes the option of a plural or selectordinal argument for its number
+func (f *messageFormatter) formatPlural(content *strings.Builder, part messagePart, value string) error {
+	value = strings.TrimSpace(value)
+	number, numberErr := strconv.ParseFloat(value, 64)
+
+	if numberErr != nil { // If the value isn't a number, use the other option
+		f.format(content, part.option(PluralOther).message, value)
+		return errors.New("Not a number")
+	}
+
+	for _, option := range part.options { // For each option, an exact match takes precedence over the category
+		if exact, exactErr := strconv.ParseFloat(strings.TrimPrefix(option.selector, "="), 64); strings.HasPrefix(option.selector, "=") && exactErr == nil && exact == number {
+			pound, _ := formatNumber(f.language, strconv.FormatFloat(number-part.offset, 'f', -1, 64), "")
+			f.format(content, option.message, pound)
+			return nil
+		}
+	}
+
+	count := value
+
+	if part.offset != 0 || !isDecimal(count) { // Normalize the number, keeping the visible fraction digits of plain decimals such as 1.0
+		count = strconv.FormatFloat(number-part.offset, 'f', -1, 64)
+	}
+
+	category, categoryErr := PluralCategory(f.language, count)
+
+	if part.kind == argumentSelectOrdinal {
+		category, categoryErr = OrdinalCategory(f.language, count)
+	}
+
+	pound, _ := formatNumber(f.language, count, "")
+	f.format(content, part.option(category).message, pound)
+	return categoryErr
+}
@@ -0,0 +1,144 @@
+// This file contains the tests of parsing and formatting ICU MessageFormat values
+
+package frala
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestFormatMessage ensures MessageFormat values are formatted with their quoting, arguments and plural rules
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		language  string
+		arguments map[string]string
+		want      string
+	}{
+		{"text", "Hello", "en", nil, "Hello"},
+		{"apostrophe", "Don't", "en", nil, "Don't"},
+		{"two apostrophes", "It''s", "en", nil, "It's"},
+		{"quoted braces", "'{name}' is literal", "en", map[string]string{"name": "x"}, "{name} is literal"},
+		{"quoted with apostrophe", "'{it''s}'", "en", nil, "{it's}"},
+		{"pound outside plural", "# {name}", "en", map[string]string{"name": "x"}, "# x"},
+		{"quoted pound in plural", "{n, plural, other {'#' #}}", "en", map[string]string{"n": "3"}, "# 3"},
+		{"unterminated quote", "'{open", "en", nil, "{open"},
+		{"simple", "Hi {name}!", "en", map[string]string{"name": "Joshua"}, "Hi Joshua!"},
+		{"missing argument", "Hi {name}!", "en", nil, "Hi {name}!"},
+		{"plural one", "{n, plural, one {# file} other {# files}}", "en", map[string]string{"n": "1"}, "1 file"},
+		{"plural other", "{n, plural, one {# file} other {# files}}", "en", map[string]string{"n": "1000"}, "1,000 files"},
+		{"plural exact", "{n, plural, =0 {no files} one {# file} other {# files}}", "en", map[string]string{"n": "0"}, "no files"},
+		{"plural offset", "{n, plural, offset:1 =0 {nobody} =1 {you} one {you and # other} other {you and # others}}", "en", map[string]string{"n": "2"}, "you and 1 other"},
+		{"plural russian", "{n, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", "ru", map[string]string{"n": "5"}, "5 файлов"},
+		{"plural french millions", "{n, plural, one {# fichier} many {# de fichiers} other {# fichiers}}", "fr", map[string]string{"n": "1000000"}, "1 000 000 de fichiers"},
+		{"plural missing category", "{n, plural, one {# file} other {# files}}", "ru", map[string]string{"n": "5"}, "5 files"},
+		{"selectordinal", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", "en", map[string]string{"n": "22"}, "22nd"},
+		{"selectordinal teen", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", "en", map[string]string{"n": "13"}, "13th"},
+		{"select", "{g, select, female {She} male {He} other {They}} left", "en", map[string]string{"g": "female"}, "She left"},
+		{"select other", "{g, select, female {She} male {He} other {They}} left", "en", map[string]string{"g": "x"}, "They left"},
+		{"nested", "{g, select, other {{n, plural, one {# item} other {# items}}}}", "en", map[string]string{"g": "x", "n": "2"}, "2 items"},
+		{"number", "{n, number}", "de", map[string]string{"n": "1234.5"}, "1.234,5"},
+		{"number integer", "{n, number, integer}", "en", map[string]string{"n": "1234.5"}, "1,235"},
+		{"number percent", "{n, number, percent}", "fi", map[string]string{"n": "0.25"}, "25%"},
+		{"date", "{d, date}", "fi", map[string]string{"d": "2024-03-05"}, "5.3.2024"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, parseErr := ParseMessage(test.value)
+
+			if parseErr != nil {
+				t.Fatal(parseErr)
+			}
+
+			if got, _, _ := message.Format(test.language, test.arguments); got != test.want {
+				t.Errorf("Format = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestParseMessageErrors ensures invalid MessageFormat syntax is reported with its offset
+func TestParseMessageErrors(t *testing.T) {
+	tests := []struct {
+		value  string
+		offset int
+	}{
+		{"{n, plural, one {# file}}", 0},               // No other option
+		{"{g, select, male {He}}", 0},                  // No other option
+		{"Hi {name", 8},                                // Unterminated argument
+		{"Hi }", 3},                                    // Unexpected }
+		{"{}", 1},                                      // No argument name
+		{"{n, currency}", 12},                          // Unknown type
+		{"{n, number, money}", 18},                     // Unknown style
+		{"{n, plural, one {a} one {b} other {c}}", 23}, // Duplicate option
+		{"{n, plural, single {a} other {b}}", 18},      // Not a plural category
+		{"{n, plural, =x {a} other {b}}", 14},          // Not a number
+		{"{n, plural, offset:x other {b}}", 20},        // Not an offset
+		{"{n, plural, other {b}", 21},                  // Unterminated options
+		{"{n, plural other {b}}", 11},                  // No comma
+	}
+
+	for _, test := range tests {
+		_, parseErr := ParseMessage(test.value)
+		var messageErr *MessageError
+
+		if !errors.As(parseErr, &messageErr) {
+			t.Errorf("ParseMessage(%q) = %v, want a MessageError", test.value, parseErr)
+		} else if messageErr.Offset != test.offset {
+			t.Errorf("ParseMessage(%q) = %v, want offset %d", test.value, messageErr, test.offset)
+		}
+	}
+}
+
+// TestFormatMessageArguments ensures missing arguments are reported, and invalid values are an ArgumentError
+func TestFormatMessageArguments(t *testing.T) {
+	message, parseErr := ParseMessage("{name} has {n, plural, one {# file} other {# files}} in {g, select, other {{folder}}}")
+
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+
+	if arguments := message.Arguments(); !reflect.DeepEqual(arguments, []string{"name", "n", "g", "folder"}) {
+		t.Errorf("Arguments = %v", arguments)
+	}
+
+	content, missing, formatErr := message.Format("en", map[string]string{"n": "many", "g": "x"})
+	var argumentErr *ArgumentError
+
+	if content != "{name} has many files in {folder}" || !reflect.DeepEqual(missing, []string{"name", "folder"}) {
+		t.Errorf("Format = %q, missing %v", content, missing)
+	}
+
+	if !errors.As(formatErr, &argumentErr) || argumentErr.Argument != "n" {
+		t.Errorf("Format error = %v, want an ArgumentError for n", formatErr)
+	}
+}
+
+// TestOrdinalCategory ensures counts get the CLDR ordinal category of each rule
+func TestOrdinalCategory(t *testing.T) {
+	tests := []struct {
+		language string
+		counts   map[string]string // Category expected for each count
+	}{
+		{"en", map[string]string{"1": "one", "21": "one", "11": "other", "2": "two", "12": "other", "3": "few", "113": "other", "4": "other"}},
+		{"fr", map[string]string{"1": "one", "2": "other"}},
+		{"hu", map[string]string{"1": "one", "5": "one", "2": "other"}},
+		{"sv", map[string]string{"1": "one", "2": "one", "11": "other", "3": "other"}},
+		{"fi", map[string]string{"1": "other"}}, // Without an ordinal rule
+	}
+
+	for _, test := range tests {
+		for count, want := range test.counts {
+			if got, categoryErr := OrdinalCategory(test.language, count); categoryErr != nil || got != want {
+				t.Errorf("OrdinalCategory(%s, %s) = %s, %v, want %s", test.language, count, got, categoryErr, want)
+			}
+		}
+	}
+
+	if _, categoryErr := OrdinalCategory("en", "first"); categoryErr == nil {
+		t.Errorf("OrdinalCategory(en, first) succeeded")
+	}
+}
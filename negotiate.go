@@ -0,0 +1,211 @@
+// This file contains functionality for negotiating the language to serve from an HTTP request
+
+package frala
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguageParameter is the query parameter and cookie which override the Accept-Language header when NegotiateOptions has none
+const DefaultLanguageParameter = "lang"
+
+// LanguagePreference is a language of an Accept-Language header, with its quality
+type LanguagePreference struct {
+	Language string  // Canonical language, or * for any language
+	Quality  float64 // Quality from 0 to 1, where 0 means the language is not acceptable
+}
+
+// NegotiateOptions are the options for negotiating the language of an HTTP request
+type NegotiateOptions struct {
+	Parameter string // Parameter is the query parameter which overrides the Accept-Language header. Defaults to DefaultLanguageParameter
+	Cookie    string // Cookie is the cookie which overrides the Accept-Language header. Defaults to DefaultLanguageParameter
+}
+
+// Negotiate gets the best language of the default Engine for an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	return Default().Negotiate(acceptLanguage)
+}
+
+// NegotiateRequest gets the best language of the default Engine for an HTTP request
+func NegotiateRequest(r *http.Request, opts NegotiateOptions) string {
+	return Default().NegotiateRequest(r, opts)
+}
+
+// SplitLanguagePrefix splits a request path starting with one of the Languages of the default Engine into the language and the rest of the path
+func SplitLanguagePrefix(urlPath string) (string, string, bool) {
+	return Default().SplitLanguagePrefix(urlPath)
+}
+
+// ParseAcceptLanguage parses an Accept-Language header, such as "fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5"
+// Returns the preferences from the highest quality to the lowest, keeping the order of the header for equal qualities. Invalid languages and qualities are skipped.
+func ParseAcceptLanguage(acceptLanguage string) []LanguagePreference {
+	var preferences []LanguagePreference
+
+	for _, item := range strings.Split(acceptLanguage, ",") { // For each language of the header
+		language, parameters, _ := strings.Cut(strings.TrimSpace(item), ";")
+		preference := LanguagePreference{Language: strings.TrimSpace(language), Quality: 1}
+
+		if preference.Language == "" {
+			continue
+		}
+
+		if preference.Language != "*" { // If this is a language, rather than any language
+			tag, tagErr := ParseTag(preference.Language)
+
+			if tagErr != nil {
+				continue
+			}
+
+			preference.Language = tag.String()
+		}
+
+		if name, value, hasValue := strings.Cut(strings.TrimSpace(parameters), "="); hasValue && strings.EqualFold(strings.TrimSpace(name), "q") { // If a quality was provided
+			quality, qualityErr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+
+			if qualityErr != nil || quality < 0 || quality > 1 {
+				continue
+			}
+
+			preference.Quality = quality
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].Quality > preferences[j].Quality
+	})
+
+	return preferences
+}
+
+// availableLanguages gets the languages the Engine can serve: the Languages of the Config, or the DefaultLanguage if there are none
+func (e *Engine) availableLanguages() []string {
+	config := e.config()
+	return config.availableLanguages()
+}
+
+// availableLanguages gets the Languages of the Config, or the DefaultLanguage if there are none
+func (c *ConfigOptions) availableLanguages() []string {
+	if len(c.Languages) == 0 {
+		return []string{c.DefaultLanguage}
+	}
+
+	return c.Languages
+}
+
+// Negotiate gets the best of the Languages of the Config for an Accept-Language header, or the DefaultLanguage if none are acceptable
+// Each preference is matched exactly, then through its fallback chain (pt-BR to pt), then against any more specific available language (en to en-GB).
+func (e *Engine) Negotiate(acceptLanguage string) string {
+	preferences := ParseAcceptLanguage(acceptLanguage)
+	excluded := make(map[string]bool)
+
+	for _, preference := range preferences { // Languages with a quality of 0 are never served
+		if preference.Quality == 0 {
+			excluded[preference.Language] = true
+		}
+	}
+
+	for _, preference := range preferences { // For each preference, from the highest quality to the lowest
+		if preference.Quality == 0 {
+			break
+		}
+
+		if language, matched := e.matchLanguage(preference.Language, excluded); matched {
+			return language
+		}
+	}
+
+	return e.config().DefaultLanguage
+}
+
+// NegotiateRequest gets the best of the Languages of the Config for an HTTP request
+// A query parameter or cookie naming an available language wins over the Accept-Language header, with the query parameter taking precedence.
+func (e *Engine) NegotiateRequest(r *http.Request, opts NegotiateOptions) string {
+	parameter, cookieName := opts.Parameter, opts.Cookie
+
+	if parameter == "" {
+		parameter = DefaultLanguageParameter
+	}
+
+	if cookieName == "" {
+		cookieName = DefaultLanguageParameter
+	}
+
+	if language := r.URL.Query().Get(parameter); language != "" { // If the query overrides the language
+		if matched, isMatched := e.matchLanguage(CanonicalLanguage(language), nil); isMatched {
+			return matched
+		}
+	}
+
+	if cookie, cookieErr := r.Cookie(cookieName); cookieErr == nil && cookie.Value != "" { // If a cookie overrides the language
+		if matched, isMatched := e.matchLanguage(CanonicalLanguage(cookie.Value), nil); isMatched {
+			return matched
+		}
+	}
+
+	return e.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// SplitLanguagePrefix splits a request path starting with one of the Languages of the Config, such as /pt-BR/about.html, into the language and the rest of the path
+// The language may be in any form of the tag, such as pt_br. Returns false if the first segment of the path isn't an available language.
+func (e *Engine) SplitLanguagePrefix(urlPath string) (string, string, bool) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(urlPath, "/"), "/")
+
+	if segment == "" {
+		return "", urlPath, false
+	}
+
+	tag, tagErr := ParseTag(segment)
+
+	if tagErr != nil || !containsString(e.availableLanguages(), tag.String()) { // If the segment isn't an available language, it is part of the path
+		return "", urlPath, false
+	}
+
+	if rest == "" && !strings.HasSuffix(urlPath, "/") { // If the path is only the language, such as /fi, leave it to be redirected to /fi/
+		return tag.String(), "", true
+	}
+
+	return tag.String(), "/" + rest, true
+}
+
+// matchLanguage matches a language against the available languages which are not excluded
+func (e *Engine) matchLanguage(language string, excluded map[string]bool) (string, bool) {
+	config := e.config()
+	available := config.availableLanguages()
+
+	isAvailable := func(candidate string) bool {
+		return !excluded[candidate] && containsString(available, candidate)
+	}
+
+	if language == "*" { // Any language, preferring the DefaultLanguage
+		if isAvailable(config.DefaultLanguage) {
+			return config.DefaultLanguage, true
+		}
+
+		for _, candidate := range available {
+			if !excluded[candidate] {
+				return candidate, true
+			}
+		}
+
+		return "", false
+	}
+
+	for _, candidate := range config.fallbackChain(language, false) { // The language itself, then its fallbacks, without the final DefaultLanguage step
+		if isAvailable(candidate) {
+			return candidate, true
+		}
+	}
+
+	for _, candidate := range available { // A more specific language, such as en-GB for en
+		if !excluded[candidate] && strings.HasPrefix(candidate, language+"-") {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
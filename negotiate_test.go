@@ -0,0 +1,137 @@
+// This file contains the tests of negotiating the language to serve from an HTTP request
+
+package frala
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// negotiateTestConfig is the Config of the Engine the negotiation tests use
+const negotiateTestConfig = `{"DefaultLanguage": "en", "Languages": ["en", "fi", "pt_BR", "de-DE"]}`
+
+// TestParseAcceptLanguage ensures preferences are canonical and ordered by quality, skipping invalid languages and qualities
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []LanguagePreference
+	}{
+		{"", nil},
+		{"fi", []LanguagePreference{{"fi", 1}}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []LanguagePreference{{"fr-CH", 1}, {"fr", 0.9}, {"en", 0.8}, {"*", 0.5}}},
+		{"en;q=0.5, fi, pt_br;q=0.5", []LanguagePreference{{"fi", 1}, {"en", 0.5}, {"pt-BR", 0.5}}}, // Equal qualities keep their order
+		{" de-de ; Q=0.7 ,sv", []LanguagePreference{{"sv", 1}, {"de-DE", 0.7}}},
+		{"fi;q=0", []LanguagePreference{{"fi", 0}}},
+		{"12, en;q=2, de;q=x, ,fi", []LanguagePreference{{"fi", 1}}},
+	}
+
+	for _, test := range tests {
+		if got := ParseAcceptLanguage(test.header); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
+
+// TestNegotiate ensures the best available language is chosen by quality, through fallbacks and more specific languages, never choosing one with a quality of 0
+func TestNegotiate(t *testing.T) {
+	engine, _ := newTestEngine(t, negotiateTestConfig, nil)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"fi", "fi"},
+		{"sv", "en"}, // Nothing acceptable is available
+		{"fr-CH, fr;q=0.9, fi;q=0.8", "fi"},
+		{"de;q=0.5, fi;q=0.9", "fi"}, // Ordered by quality rather than position
+		{"de", "de-DE"},              // More specific
+		{"fi-FI", "fi"},              // Parent
+		{"pt", "pt-BR"},
+		{"pt-PT", "en"},
+		{"sv, *;q=0.1", "en"}, // Any language, preferring the DefaultLanguage
+		{"en;q=0, *", "fi"},
+		{"fi;q=0, fi-FI", "en"},
+		{"de-DE;q=0, de", "en"},
+		{"en;q=0, fi;q=0, pt-BR;q=0, de-DE;q=0, *", "en"},
+		{"fi;q=1.5, de", "de-DE"},
+		{"fi;q=abc, de-DE;q=0.2", "de-DE"},
+		{"sv, FI;Q=0.3", "fi"},
+	}
+
+	for _, test := range tests {
+		if got := engine.Negotiate(test.header); got != test.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", test.header, got, test.want)
+		}
+	}
+}
+
+// TestNegotiateRequest ensures a query parameter, then a cookie, naming an available language wins over the Accept-Language header
+func TestNegotiateRequest(t *testing.T) {
+	engine, _ := newTestEngine(t, negotiateTestConfig, nil)
+
+	tests := []struct {
+		name   string
+		target string
+		cookie *http.Cookie
+		header string
+		opts   NegotiateOptions
+		want   string
+	}{
+		{"header", "/", nil, "de", NegotiateOptions{}, "de-DE"},
+		{"parameter", "/?lang=fi", nil, "de", NegotiateOptions{}, "fi"},
+		{"parameter form", "/?lang=pt_br", nil, "", NegotiateOptions{}, "pt-BR"},
+		{"parameter unavailable", "/?lang=sv", nil, "de", NegotiateOptions{}, "de-DE"},
+		{"cookie", "/", &http.Cookie{Name: "lang", Value: "fi"}, "de", NegotiateOptions{}, "fi"},
+		{"parameter before cookie", "/?lang=pt-BR", &http.Cookie{Name: "lang", Value: "fi"}, "de", NegotiateOptions{}, "pt-BR"},
+		{"unavailable parameter then cookie", "/?lang=sv", &http.Cookie{Name: "lang", Value: "fi"}, "de", NegotiateOptions{}, "fi"},
+		{"custom names", "/?l=fi", &http.Cookie{Name: "locale", Value: "de"}, "en", NegotiateOptions{Parameter: "l", Cookie: "locale"}, "fi"},
+		{"custom cookie", "/?lang=fi", &http.Cookie{Name: "locale", Value: "de"}, "en", NegotiateOptions{Parameter: "l", Cookie: "locale"}, "de-DE"},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, test.target, nil)
+
+		if test.cookie != nil {
+			r.AddCookie(test.cookie)
+		}
+
+		if test.header != "" {
+			r.Header.Set("Accept-Language", test.header)
+		}
+
+		if got := engine.NegotiateRequest(r, test.opts); got != test.want {
+			t.Errorf("%s: NegotiateRequest = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+// TestSplitLanguagePrefix ensures only an available language in the first segment of a path is split from the rest
+func TestSplitLanguagePrefix(t *testing.T) {
+	engine, _ := newTestEngine(t, negotiateTestConfig, nil)
+
+	tests := []struct {
+		path     string
+		language string
+		rest     string
+		split    bool
+	}{
+		{"/fi/about.html", "fi", "/about.html", true},
+		{"/pt_br/docs/", "pt-BR", "/docs/", true},
+		{"/fi/", "fi", "/", true},
+		{"/fi", "fi", "", true}, // Left to be redirected to /fi/
+		{"/about.html", "", "/about.html", false},
+		{"/sv/about.html", "", "/sv/about.html", false},
+		{"/", "", "/", false},
+	}
+
+	for _, test := range tests {
+		language, rest, split := engine.SplitLanguagePrefix(test.path)
+
+		if language != test.language || rest != test.rest || split != test.split {
+			t.Errorf("SplitLanguagePrefix(%q) = %q, %q, %v, want %q, %q, %v", test.path, language, rest, split, test.language, test.rest, test.split)
+		}
+	}
+}
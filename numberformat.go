@@ -0,0 +1,164 @@
+// This file contains functionality for formatting the numbers, dates and times of MessageFormat arguments
+
+package frala
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberSeparators are the group and decimal separators of numbers, keyed by language. Languages without separators use , and .
+var numberSeparators = map[string][2]string{}
+
+// dateLayouts are the layouts of dates, keyed by language. Languages without a layout use ISO 8601 (2006-01-02)
+var dateLayouts = map[string]string{}
+
+// twelveHourLanguages are the languages using 12-hour times, such as 3:04 PM. Other languages use 15:04
+var twelveHourLanguages = map[string]bool{}
+
+// registerNumberSeparators registers the group and decimal separators of numbers for each of the languages provided
+func registerNumberSeparators(languages, group, decimal string) {
+	for _, language := range strings.Split(languages, ",") { // For each language
+		numberSeparators[language] = [2]string{group, decimal}
+	}
+}
+
+// registerDateLayout registers the layout of dates for each of the languages provided
+func registerDateLayout(languages, layout string) {
+	for _, language := range strings.Split(languages, ",") { // For each language
+		dateLayouts[language] = layout
+	}
+}
+
+func init() {
+	registerNumberSeparators("da,de,el,es,id,it,nl,pt,ro,sl,sr,hr,tr,vi", ".", ",")
+	registerNumberSeparators("be,bg,cs,et,fi,fr,hu,lt,lv,nb,nn,no,pl,ru,sk,sv,uk", "\u00a0", ",")
+
+	registerDateLayout("en", "1/2/2006")
+	registerDateLayout("be,cs,de,fi,nb,nn,no,pl,ru,sk,uk", "2.1.2006")
+	registerDateLayout("el,es,it,vi", "2/1/2006")
+	registerDateLayout("ar,fr,he,iw,pt,ga", "02/01/2006")
+	registerDateLayout("nl", "2-1-2006")
+	registerDateLayout("ja,zh", "2006/01/02")
+	registerDateLayout("ko", "2006. 1. 2.")
+	registerDateLayout("hu", "2006. 01. 02.")
+
+	for _, language := range []string{"ar", "bn", "en", "hi", "ko", "ur"} {
+		twelveHourLanguages[language] = true
+	}
+}
+
+// isDecimal returns whether the value is a plain decimal number, such as -1234.50
+func isDecimal(value string) bool {
+	integer, fraction, hasFraction := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+	return integer != "" && isDigits(integer) && (!hasFraction || (fraction != "" && isDigits(fraction)))
+}
+
+// formatNumber formats a number for a language with the style of a number argument: none, integer or percent
+func formatNumber(language, value, style string) (string, error) {
+	value = strings.TrimSpace(value)
+	number, numberErr := strconv.ParseFloat(value, 64)
+
+	if numberErr != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return value, errors.New("Not a number")
+	}
+
+	suffix := ""
+
+	switch {
+	case style == "integer":
+		value = strconv.FormatFloat(math.Round(number), 'f', 0, 64)
+	case style == "percent":
+		value = strconv.FormatFloat(math.Round(number*100), 'f', 0, 64)
+		suffix = "%"
+	case !isDecimal(value): // Normalize numbers such as 1e3, keeping the visible fraction digits of plain decimals
+		value = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	separators, exists := numberSeparators[primaryLanguage(language)]
+
+	if !exists {
+		separators = [2]string{",", "."}
+	}
+
+	sign := ""
+
+	if strings.HasPrefix(value, "-") {
+		sign, value = "-", value[1:]
+	}
+
+	integer, fraction, hasFraction := strings.Cut(value, ".")
+	var grouped strings.Builder
+
+	for index, digit := range integer { // For each digit, separating groups of three
+		if index != 0 && (len(integer)-index)%3 == 0 {
+			grouped.WriteString(separators[0])
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	if hasFraction {
+		grouped.WriteString(separators[1] + fraction)
+	}
+
+	return sign + grouped.String() + suffix, nil
+}
+
+// parseDateTime parses an RFC 3339 timestamp, a date, a time, or Unix seconds
+func parseDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "15:04:05", "15:04"} {
+		if parsed, parseErr := time.Parse(layout, value); parseErr == nil {
+			return parsed, nil
+		}
+	}
+
+	if seconds, secondsErr := strconv.ParseInt(value, 10, 64); secondsErr == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Time{}, errors.New("Not an RFC 3339 timestamp, date, time or Unix seconds")
+}
+
+// formatDateTime formats the date or time of a date or time argument for a language
+// Frala has no localized month or day names, so every date style uses the numeric layout of the language. Times include seconds unless the style is short.
+func formatDateTime(language, value, kind, style string) (string, error) {
+	parsed, parseErr := parseDateTime(value)
+
+	if parseErr != nil {
+		return value, parseErr
+	}
+
+	language = primaryLanguage(language)
+
+	if kind == argumentDate {
+		layout, exists := dateLayouts[language]
+
+		if !exists {
+			layout = "2006-01-02"
+		}
+
+		return parsed.Format(layout), nil
+	}
+
+	layout := "15:04"
+
+	if twelveHourLanguages[language] {
+		layout = "3:04"
+	}
+
+	if style != "short" {
+		layout += ":05"
+	}
+
+	if twelveHourLanguages[language] {
+		layout += " PM"
+	}
+
+	return parsed.Format(layout), nil
+}
@@ -0,0 +1,233 @@
+// This file contains functionality for choosing the CLDR ordinal category of a count, such as 1st, 2nd and 3rd
+
+package frala
+
+import (
+	"strings"
+)
+
+// ordinalRules are the CLDR ordinal plural rules, keyed by language. Languages without a rule only use PluralOther
+var ordinalRules = map[string]pluralRule{}
+
+// registerOrdinalRule registers an ordinal rule for each of the languages provided
+func registerOrdinalRule(languages string, categories []string, category func(o pluralOperands) string) {
+	for _, language := range strings.Split(languages, ",") { // For each language
+		ordinalRules[language] = pluralRule{categories: categories, category: category}
+	}
+}
+
+func init() {
+	registerOrdinalRule("en", []string{PluralOne, PluralTwo, PluralFew, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.isInt && o.i%10 == 1 && o.i%100 != 11:
+			return PluralOne
+		case o.isInt && o.i%10 == 2 && o.i%100 != 12:
+			return PluralTwo
+		case o.isInt && o.i%10 == 3 && o.i%100 != 13:
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("fil,fr,ga,hy,lo,ms,ro,tl,vi", []string{PluralOne, PluralOther}, func(o pluralOperands) string {
+		if o.n(1) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("hu", []string{PluralOne, PluralOther}, func(o pluralOperands) string {
+		if o.n(1, 5) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("sv", []string{PluralOne, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && (o.i%10 == 1 || o.i%10 == 2) && o.i%100 != 11 && o.i%100 != 12 {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("ne", []string{PluralOne, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && inRange(o.i, 1, 4) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("it,sc", []string{PluralMany, PluralOther}, func(o pluralOperands) string {
+		if o.n(11, 8, 80, 800) {
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("ca", []string{PluralOne, PluralTwo, PluralFew, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1, 3):
+			return PluralOne
+		case o.n(2):
+			return PluralTwo
+		case o.n(4):
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("gu,hi", []string{PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1):
+			return PluralOne
+		case o.n(2, 3):
+			return PluralTwo
+		case o.n(4):
+			return PluralFew
+		case o.n(6):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("as,bn", []string{PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1, 5, 7, 8, 9, 10):
+			return PluralOne
+		case o.n(2, 3):
+			return PluralTwo
+		case o.n(4):
+			return PluralFew
+		case o.n(6):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("mr", []string{PluralOne, PluralTwo, PluralFew, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1):
+			return PluralOne
+		case o.n(2, 3):
+			return PluralTwo
+		case o.n(4):
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("cy", []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(0, 7, 8, 9):
+			return PluralZero
+		case o.n(1):
+			return PluralOne
+		case o.n(2):
+			return PluralTwo
+		case o.n(3, 4):
+			return PluralFew
+		case o.n(5, 6):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("sq", []string{PluralOne, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1):
+			return PluralOne
+		case o.isInt && o.i%10 == 4 && o.i%100 != 14:
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("mk", []string{PluralOne, PluralTwo, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.i%10 == 1 && o.i%100 != 11:
+			return PluralOne
+		case o.i%10 == 2 && o.i%100 != 12:
+			return PluralTwo
+		case (o.i%10 == 7 || o.i%10 == 8) && o.i%100 != 17 && o.i%100 != 18:
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("uk", []string{PluralFew, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && o.i%10 == 3 && o.i%100 != 13 {
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("be", []string{PluralFew, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && (o.i%10 == 2 || o.i%10 == 3) && o.i%100 != 12 && o.i%100 != 13 {
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("kk", []string{PluralMany, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && (o.i%10 == 6 || o.i%10 == 9 || (o.i%10 == 0 && o.i != 0)) {
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("tk", []string{PluralFew, PluralOther}, func(o pluralOperands) string {
+		if o.isInt && (o.i%10 == 6 || o.i%10 == 9 || o.i == 10) {
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerOrdinalRule("ka", []string{PluralOne, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.i == 1:
+			return PluralOne
+		case o.i == 0 || inRange(o.i%100, 2, 20) || o.i%100 == 40 || o.i%100 == 60 || o.i%100 == 80:
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+}
+
+// ordinalRuleOf gets the ordinal rule of a language, using PluralOther alone for languages without a built-in rule
+func ordinalRuleOf(language string) pluralRule {
+	if rule, exists := ordinalRules[primaryLanguage(language)]; exists {
+		return rule
+	}
+
+	return pluralRules["ja"]
+}
+
+// OrdinalCategory gets the CLDR ordinal category of a count in a language, such as "two" for 2 (2nd) in en
+// Returns PluralOther for languages without a built-in rule, and an error if the count is not a number.
+func OrdinalCategory(language, count string) (string, error) {
+	operands, operandsErr := newPluralOperands(count)
+
+	if operandsErr != nil {
+		return PluralOther, operandsErr
+	}
+
+	return ordinalRuleOf(language).category(operands), nil
+}
@@ -3,156 +3,608 @@
 package frala
 
 import (
+	"bytes"
 	"errors"
-	"github.com/StroblIndustries/coreutils"
+	"io"
 	"io/ioutil"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // MultiParse
-// Parses multiple files provided and return a map of ParseResponses
+// Parses multiple files provided with the default Engine and return a map of ParseResponses
 func MultiParse(files []string) []ParseResponse {
+	return Default().MultiParse(files)
+}
+
+// MultilingualParse
+// Parses all provided files using all available languages of the default Engine
+// Returns a map of languages cooresponding to an array of ParseResponse
+func MultilingualParse(files []string) map[string][]ParseResponse {
+	return Default().MultilingualParse(files)
+}
+
+// Parse
+// Parses a file with the default Engine
+func Parse(file string) ParseResponse {
+	return Default().Parse(file)
+}
+
+// Document Parse
+// Parses each Node of a Document with the default Engine and returns the content and any Diagnostics
+func (d *Document) Parse() (string, []ParseError) {
+	return Default().ParseNodes(d)
+}
+
+// ParseReader
+// Parses the content of a reader with the default Engine, using name for Fragment resolution and Diagnostics
+func ParseReader(name string, reader io.Reader) ParseResponse {
+	return Default().ParseReader(name, reader)
+}
+
+// Render
+// Parses a file with the default Engine, writing the content to w as it is parsed
+func Render(w io.Writer, file string, opts RenderOptions) ([]ParseError, error) {
+	return Default().Render(w, file, opts)
+}
+
+// Context Parse
+// Parses a Frala context with the default Engine and returns a string, along with Diagnostics for any problems
+func (c *Context) Parse() (string, []ParseError) {
+	return Default().ParseContext(c)
+}
+
+// RenderOptions are the options for a single parse of a file
+type RenderOptions struct {
+	Language     string            // Language to parse Terms in. Defaults to the CurrentLanguage of the Config
+	Data         map[string]string // Data fills {name} placeholders in Term values, unless the Frala syntax provides an attribute of the same name
+	Dependencies *Dependencies     // Dependencies, if provided, is set to the files and Terms the parse used
+	Strict       bool              // Strict is whether any Diagnostic fails the parse, as when Strict is set in the Config
+}
+
+// renderer is the state of a single parse, so that parses of an Engine can run concurrently
+type renderer struct {
+	engine   *Engine                    // Engine providing the Config and Terms
+	config   ConfigOptions              // Settings of the Config when the parse started, so reloading the Config doesn't change them partway through
+	language string                     // Language Terms are parsed in
+	data     map[string]string          // Data filling placeholders in Term values
+	includes []string                   // Paths of the files currently being parsed, from the outermost file to the innermost Fragment
+	files    map[string]bool            // Paths of every file the parse read, or attempted to read
+	terms    map[string]map[string]bool // Names of every Term the parse used, with the languages each was looked up in
+}
+
+// newRenderer creates a renderer for the RenderOptions provided
+func (e *Engine) newRenderer(opts RenderOptions) *renderer {
+	return &renderer{engine: e, config: e.config(), language: e.renderLanguage(opts), data: opts.Data, files: make(map[string]bool), terms: make(map[string]map[string]bool)}
+}
+
+// renderLanguage gets the language Terms are parsed in for the RenderOptions provided
+func (e *Engine) renderLanguage(opts RenderOptions) string {
+	if opts.Language == "" { // If no language was provided
+		return e.config().CurrentLanguage
+	}
+
+	return CanonicalLanguage(opts.Language)
+}
+
+// errWriter is an io.Writer that keeps the first error of the writer it wraps, ignoring any writes after it
+type errWriter struct {
+	w   io.Writer // Writer being wrapped
+	err error     // First error returned by w
+}
+
+// Write writes p to the wrapped writer, unless it has already returned an error
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil { // If a previous write failed
+		return 0, ew.err
+	}
+
+	var n int
+	n, ew.err = ew.w.Write(p)
+	return n, ew.err
+}
+
+// MultiParse
+// Parses multiple files provided and return a map of ParseResponses
+func (e *Engine) MultiParse(files []string) []ParseResponse {
 	var parseResponses []ParseResponse
 
-	for _, file := range files { // For each file
-		if strings.HasSuffix(file, ".html") { // If this is an HTML file
-			parseResponses = append(parseResponses, Parse(file))
-		}
+	for _, file := range htmlFiles(files) { // For each HTML file
+		parseResponses = append(parseResponses, e.Parse(file))
 	}
 
 	return parseResponses
 }
 
+// parseJob is a single language and file to parse in MultilingualParse
+type parseJob struct {
+	language string // Language to parse the file in
+	index    int    // Index of the file, which is also the index of its ParseResponse
+	file     string // File to parse
+}
+
 // MultilingualParse
-// Parses all provided files using all available languages
-// Returns a map of languages cooresponding to an array of ParseResponse
-func MultilingualParse(files []string) map[string][]ParseResponse {
+// Parses all provided files using all available languages (the DefaultLanguage if the Config has no Languages), parsing up to Parallelism files concurrently
+// Returns a map of languages cooresponding to an array of ParseResponse, in the same order as files
+func (e *Engine) MultilingualParse(files []string) map[string][]ParseResponse {
+	files = htmlFiles(files)
 	parserResponses := make(map[string][]ParseResponse)
+	jobs := make(chan parseJob)
+
+	var workers sync.WaitGroup
 
-	for _, lang := range Config.Languages { // For each of our languages
-		Config.CurrentLanguage = lang
-		parserResponses[lang] = MultiParse(files)
+	for _, lang := range e.availableLanguages() { // For each of our languages
+		parserResponses[CanonicalLanguage(lang)] = make([]ParseResponse, len(files)) // Allocate up front, so each worker only writes its own index
 	}
 
+	for worker := 0; worker < e.parallelism(); worker++ { // Start each worker
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for job := range jobs { // For each job until there are none left
+				parserResponses[job.language][job.index] = e.ParseWith(job.file, RenderOptions{Language: job.language})
+			}
+		}()
+	}
+
+	for lang := range parserResponses { // For each unique language
+		for index, file := range files { // For each file
+			jobs <- parseJob{language: lang, index: index, file: file}
+		}
+	}
+
+	close(jobs)
+	workers.Wait()
+
 	return parserResponses
 }
 
+// parallelism gets the number of files MultilingualParse parses concurrently
+func (e *Engine) parallelism() int {
+	if e.Parallelism > 0 { // If a limit was configured
+		return e.Parallelism
+	}
+
+	return runtime.NumCPU()
+}
+
+// htmlFiles filters the files to only those which are HTML files
+func htmlFiles(files []string) []string {
+	var html []string
+
+	for _, file := range files { // For each file
+		if strings.HasSuffix(file, ".html") { // If this is an HTML file
+			html = append(html, file)
+		}
+	}
+
+	return html
+}
+
 // Parse
-// Parses a file
-func Parse(file string) ParseResponse {
-	var contentBytes []byte
-	var parseResponse ParseResponse
+// Parses a file in the CurrentLanguage of the Config
+func (e *Engine) Parse(file string) ParseResponse {
+	return e.ParseWith(file, RenderOptions{})
+}
+
+// ParseWith
+// Parses a file with the RenderOptions provided, such as the language to parse it in
+// Any problems found are collected as Diagnostics. When Strict is set in the Config or the RenderOptions, any Diagnostic fails the parse.
+func (e *Engine) ParseWith(file string, opts RenderOptions) ParseResponse {
+	var content strings.Builder
+
+	parseResponse := ParseResponse{Name: file, Language: e.renderLanguage(opts)}
+	opts.Dependencies = &parseResponse.Dependencies
+	parseResponse.Diagnostics, parseResponse.Error = e.Render(&content, file, opts)
+	parseResponse.Content = content.String()
+
+	return parseResponse
+}
 
-	parseResponse.Name = file
-	contentBytes, parseResponse.Error = ioutil.ReadFile(file) // Read the file, set any read error to parseResponse.Error
+// ParseReader
+// Parses the content of a reader in the CurrentLanguage of the Config, using name for Fragment resolution and Diagnostics
+func (e *Engine) ParseReader(name string, reader io.Reader) ParseResponse {
+	var content strings.Builder
+
+	parseResponse := ParseResponse{Name: name, Language: e.renderLanguage(RenderOptions{})}
+	parseResponse.Diagnostics, parseResponse.Error = e.RenderReader(&content, name, reader, RenderOptions{Dependencies: &parseResponse.Dependencies})
+	parseResponse.Content = content.String()
+
+	return parseResponse
+}
+
+// Render
+// Parses a file with the RenderOptions provided, writing the content to w as it is parsed
+// Returns any Diagnostics and the error which failed the parse, such as a read, syntax or write error.
+// When Strict is set in the Config or the RenderOptions, the content is only written if there are no Diagnostics.
+func (e *Engine) Render(w io.Writer, file string, opts RenderOptions) ([]ParseError, error) {
+	return e.render(w, opts, func(r *renderer, out io.Writer) ([]ParseError, error) {
+		return r.renderFile(out, file)
+	})
+}
 
-	if len(contentBytes) == 0 || parseResponse.Error != nil {
-		parseResponse.Error = errors.New("Failed to read: " + file)
-		return parseResponse
+// RenderReader
+// Parses the content of a reader with the RenderOptions provided, writing the content to w as it is parsed
+// The name is used for Fragment resolution and Diagnostics. Otherwise, it is the same as Render.
+func (e *Engine) RenderReader(w io.Writer, name string, reader io.Reader, opts RenderOptions) ([]ParseError, error) {
+	return e.render(w, opts, func(r *renderer, out io.Writer) ([]ParseError, error) {
+		content, readErr := ioutil.ReadAll(reader)
+
+		if readErr != nil { // If we failed to read the content
+			return nil, errors.New("Failed to read: " + name + ": " + readErr.Error())
+		}
+
+		return r.renderContent(out, name, content)
+	})
+}
+
+// render runs renderFunc with a new renderer, handling Strict mode and any write error
+func (e *Engine) render(w io.Writer, opts RenderOptions, renderFunc func(*renderer, io.Writer) ([]ParseError, error)) ([]ParseError, error) {
+	var buffer bytes.Buffer
+	out := &errWriter{w: w}
+	r := e.newRenderer(opts)
+	strict := r.config.Strict || opts.Strict
+
+	if strict { // If any Diagnostic should fail the parse, buffer the content until we know there are none
+		out = &errWriter{w: &buffer}
 	}
 
-	content := string(contentBytes[:])
-	lines := strings.Split(content, "\n") // Split the string up into lines
-	newLines := []string{}                // Create a newLines slice that'll contain our content
+	diagnostics, renderErr := renderFunc(r, out)
 
-	for _, line := range lines { // For each line
-		if strings.Contains(line, "{{") && strings.Contains(line, "}}") { // Frala syntax
-			var newLineContent string // Define newLineContent as the new line content we will return
+	if opts.Dependencies != nil { // If the files and Terms used should be provided
+		*opts.Dependencies = r.dependencies()
+	}
 
-			multiSyntaxSplit := strings.Split(line, "{{") // Split the line into segments based on {{
+	if renderErr == nil && out.err != nil { // If we failed to write the content
+		renderErr = out.err
+	}
 
-			for _, lineSegment := range multiSyntaxSplit { // For each segment of the line
-				var parsedContext string
-				var context Context
+	if strict && renderErr == nil { // If we buffered the content
+		if len(diagnostics) != 0 { // If any Diagnostic should fail the parse
+			return diagnostics, &diagnostics[0] // Fail with the first Diagnostic, ensuring broken content is never written
+		}
 
-				syntaxEndSplit := strings.Split(lineSegment, "}}") // Split the segment based on the ending of the Frala syntax
-				fralaSyntaxContent := strings.TrimSpace(syntaxEndSplit[0])
-				fralaSyntaxProperties := strings.Split(fralaSyntaxContent, " ") // Split on whitespace
+		_, renderErr = buffer.WriteTo(w)
+	}
 
-				for _, property := range fralaSyntaxProperties {
-					propertyValueSplit := strings.Split(strings.Replace(property, "\"", "", -1), "=")
-					property := propertyValueSplit[0]
-					value := propertyValueSplit[1]
+	return diagnostics, renderErr
+}
 
-					if property == "lang" {
-						context.Lang = value
-					} else if property == "src" {
-						context.Source = value
-					} else if property == "type" {
-						context.Type = value
-					}
-				}
+// dependencies gets the files and Terms used by the parse, sorted
+func (r *renderer) dependencies() Dependencies {
+	dependencies := Dependencies{Terms: make(map[string][]string)}
 
-				parsedContext += context.Parse()
-				contentAfterFralaSyntax := syntaxEndSplit[1]
+	for file := range r.files {
+		dependencies.Files = append(dependencies.Files, file)
+	}
 
-				newLineContent += parsedContext + contentAfterFralaSyntax
-			}
+	for term, languages := range r.terms { // For each Term, with the languages it was looked up in
+		for language := range languages {
+			dependencies.Terms[term] = append(dependencies.Terms[term], language)
+		}
+
+		sort.Strings(dependencies.Terms[term])
+	}
+
+	sort.Strings(dependencies.Files)
+	return dependencies
+}
+
+// ParseNodes
+// Parses each Node of a Document and returns the content and any Diagnostics
+func (e *Engine) ParseNodes(d *Document) (string, []ParseError) {
+	var content strings.Builder
+	diagnostics := e.newRenderer(RenderOptions{}).renderNodes(&content, d)
+
+	return content.String(), diagnostics
+}
+
+// ParseContext
+// Parses a Frala context and returns a string, along with Diagnostics for any problems
+// A Context with problems returns an empty string, so error messages never end up in content
+func (e *Engine) ParseContext(c *Context) (string, []ParseError) {
+	var content strings.Builder
+	diagnostics := e.newRenderer(RenderOptions{}).renderContext(&content, c)
+
+	return content.String(), diagnostics
+}
+
+// reservedAttributes are the attributes of Frala syntax which are not Arguments
+var reservedAttributes = map[string]bool{"count": true, "lang": true, "src": true, "type": true}
+
+// NewContext
+// Creates a Context from the attributes of a TagNode declared in file
+func NewContext(file string, node Node) Context {
+	context := Context{File: file, Node: node, Arguments: make(map[string]string)}
+	context.Lang, _ = node.Attribute("lang")
+	context.Source, _ = node.Attribute("src")
+	context.Type, _ = node.Attribute("type")
+	context.Count, _ = node.Attribute("count")
 
-			newLines = append(newLines, newLineContent)
-		} else { // Not Frala syntax
-			newLines = append(newLines, line)
+	for _, attribute := range node.Attributes { // For each attribute, any which aren't reserved are Arguments
+		if !reservedAttributes[attribute.Name] {
+			context.Arguments[attribute.Name] = attribute.Value
 		}
 	}
 
-	parseResponse.Content = strings.Join(newLines, "\n")
-	return parseResponse
+	return context
 }
 
-// Context Parse
-// Parses a Frala context and returns a string
-func (c *Context) Parse() string {
+// renderFile renders the Document of a file to w, using the Cache of the Engine
+func (r *renderer) renderFile(w io.Writer, file string) ([]ParseError, error) {
+	if includePath, pathErr := r.engine.includePath(file); pathErr == nil { // Track the file as a dependency, even if it can't be read, so creating it can be detected
+		r.files[includePath] = true
+	}
+
+	return r.renderDocument(w, file, func() (*Document, error) {
+		return r.engine.loadDocument(file)
+	})
+}
+
+// renderContent tokenizes and parses content, rendering it to w
+func (r *renderer) renderContent(w io.Writer, name string, content []byte) ([]ParseError, error) {
+	return r.renderDocument(w, name, func() (*Document, error) {
+		return ParseDocument(name, string(content))
+	})
+}
+
+// renderDocument renders the Document returned by load to w, tracking name as being parsed
+func (r *renderer) renderDocument(w io.Writer, name string, load func() (*Document, error)) ([]ParseError, error) {
+	document, loadErr := load()
+
+	if parseErr, isParseErr := loadErr.(*ParseError); isParseErr { // If the Frala syntax is malformed
+		return []ParseError{*parseErr}, parseErr
+	} else if loadErr != nil { // If the file could not be read
+		return nil, loadErr
+	}
+
+	if includePath, pathErr := r.engine.includePath(name); pathErr == nil { // Track the file as being parsed, for include cycle detection
+		r.includes = append(r.includes, includePath)
+		defer func() { r.includes = r.includes[:len(r.includes)-1] }()
+	}
+
+	return r.renderNodes(w, document), nil
+}
+
+// renderNodes renders each Node of a Document to w, returning any Diagnostics
+func (r *renderer) renderNodes(w io.Writer, d *Document) []ParseError {
+	diagnostics := append([]ParseError(nil), d.Warnings...) // Copied, since the Document may be cached
+
+	for _, node := range d.Nodes { // For each Node
+		if node.Type == TextNode { // Not Frala syntax
+			io.WriteString(w, node.Text)
+		} else { // Frala syntax
+			context := NewContext(d.Name, node)
+			diagnostics = append(diagnostics, r.renderContext(w, &context)...)
+		}
+	}
+
+	return diagnostics
+}
+
+// renderContext renders a Frala context to w, returning Diagnostics for any problems
+func (r *renderer) renderContext(w io.Writer, c *Context) []ParseError {
+	e := r.engine
+
 	if c.Lang == "" && c.Type == "term" { // If Lang isn't set for term
-		c.Lang = Config.CurrentLanguage // Set to the current parsing language.
-	} else if c.Source == "" { // No Source set
-		return "Source for this Frala syntax not specified."
+		c.Lang = r.language // Set to the current parsing language.
+	}
+
+	if c.Source == "" { // No Source set
+		return []ParseError{c.newParseError(ErrorMissingSource, "Source for this Frala syntax not specified.")}
 	} else if c.Type == "" { // No Type set
-		return "Type for this Frala syntax not specified."
+		return []ParseError{c.newParseError(ErrorMissingType, "Type for this Frala syntax not specified.")}
 	}
 
 	var parsedContext string
+	var diagnostics []ParseError
 
 	if c.Type == "fragment" { // If this is a Fragment
-		if c.Source != CurrentParsingFile { // If we're not doing some crazy import fragment within itself sorcery
-			restoreFileName := CurrentParsingFile // Set restoreFileName to CurrentParsingFile before doing any potential crazy business
-			c.Source = coreutils.AbsPath(CurrentParsingFile) + c.Source
-
-			fragmentParserResponse := Parse(c.Source) // Pass the Fragment file path to our Parse
-			CurrentParsingFile = restoreFileName      // Restore file name back to original state
-
-			if fragmentParserResponse.Error == nil { // If there was no error reading the fragment file
-				parsedContext = fragmentParserResponse.Content // Set parsedContext to fragment ParserResponse Content
-			} else { // If the fragment file does not exist
-				parsedContext = fragmentParserResponse.Error.Error() // Get the parser response error
-			}
-		} else { // If we're attempting Fragment inception
-			parsedContext = "Cannot import " + c.Source + " within itself."
-		}
+		return r.renderFragment(w, c)
 	} else if c.Type == "term" { // If this is a term
 		switch c.Source {
 		case "frala.CurrentLanguage":
-			parsedContext = Config.CurrentLanguage
+			parsedContext = r.language
 			break
 		case "frala.DefaultLanguage":
-			parsedContext = Config.DefaultLanguage
+			parsedContext = r.config.DefaultLanguage
 			break
 		case "frala.Direction":
-			parsedContext = Config.Direction
+			parsedContext = e.Direction(r.language)
 			break
 		case "frala.Languages":
-			if len(Config.Languages) != 0 { // If there was languages defined in the Config
-				parsedContext = strings.Join(Config.Languages, ",")
+			if len(r.config.Languages) != 0 { // If there was languages defined in the Config
+				parsedContext = strings.Join(r.config.Languages, ",")
 			} else { // If there are no languages defined in the Config.Languages
-				parsedContext = Config.DefaultLanguage // Return the DefaultLanguage instead
+				parsedContext = r.config.DefaultLanguage // Return the DefaultLanguage instead
 			}
 			break
 		default:
-			parsedContext = GetValue(c.Source, c.Lang) // Get the Language value of this Source in Terms
+			parsedContext, diagnostics = r.parseTerm(c)
 			break
 		}
 	} else {
-		parsedContext = c.Type + " is not a valid type."
+		diagnostics = []ParseError{c.newParseError(ErrorInvalidType, c.Type+" is not a valid type.")}
+	}
+
+	io.WriteString(w, parsedContext)
+	return diagnostics
+}
+
+// parseTerm parses a Term of a Context, choosing the plural form for its Count and filling any placeholders
+func (r *renderer) parseTerm(c *Context) (string, []ParseError) {
+	var diagnostics []ParseError
+
+	if _, tagErr := ParseTag(c.Lang); tagErr != nil { // If the language isn't a valid tag
+		return "", []ParseError{c.newParseError(ErrorInvalidLanguage, tagErr.Error())}
+	}
+
+	language := CanonicalLanguage(c.Lang)
+	if r.terms[c.Source] == nil {
+		r.terms[c.Source] = make(map[string]bool)
+	}
+
+	r.terms[c.Source][language] = true
+	value, used, exists := r.engine.lookupFallback(c.Source, language)
+
+	if !exists { // If the Term is not translated into this language or any of its fallbacks
+		return "", []ParseError{c.newParseError(ErrorUntranslated, "Term "+c.Source+" is not translated into "+language+" or any of its fallbacks")}
+	}
+
+	if used != language { // If we are falling back to another language, report it so it can be translated
+		r.engine.recordFallback(Fallback{Term: c.Source, Language: language, Used: used, File: c.File, Line: c.Node.Pos.Line, Column: c.Node.Pos.Column})
+	}
+
+	form := value.Text
+
+	if value.IsPlural() && c.Count == "" { // If there is no Count to choose the form with
+		form = value.String()
+		diagnostics = append(diagnostics, c.newParseError(ErrorPlural, "Term "+c.Source+" has plural forms, but no count was specified"))
+	} else if value.IsPlural() { // If we should choose the form for the Count
+		var formErr error
+
+		if form, formErr = value.Form(used, c.Count); formErr != nil { // If the Count is invalid or has no form in this language
+			diagnostics = append(diagnostics, c.newParseError(ErrorPlural, "Term "+c.Source+": "+formErr.Error()))
+		}
+	}
+
+	message, messageErr := ParseMessage(form)
+
+	if messageErr != nil { // If the value isn't valid MessageFormat syntax
+		return "", append(diagnostics, c.newParseError(ErrorMessage, "Term "+c.Source+" has invalid syntax in "+used+": "+messageErr.Error()))
+	}
+
+	formatted, missing, formatErr := message.Format(used, r.arguments(c)) // Format in the language of the value, so its plural rules apply
+	reported := make(map[string]bool)
+
+	for _, name := range missing { // For each missing placeholder, reported once
+		if !reported[name] {
+			reported[name] = true
+			diagnostics = append(diagnostics, c.newParseError(ErrorPlaceholderMissing, "Term "+c.Source+" has no value for placeholder {"+name+"} in "+language))
+		}
+	}
+
+	if formatErr != nil { // If an argument has an invalid value
+		diagnostics = append(diagnostics, c.newParseError(ErrorMessage, "Term "+c.Source+": "+formatErr.Error()))
 	}
 
-	return parsedContext
+	for _, attribute := range c.Node.Attributes { // For each Argument in source order, ensure it is used by a form of the value
+		if _, isArgument := c.Arguments[attribute.Name]; isArgument && !valueUsesPlaceholder(value, attribute.Name) {
+			diagnostics = append(diagnostics, c.newParseError(ErrorPlaceholderUnused, "Term "+c.Source+" has no placeholder {"+attribute.Name+"} in "+language))
+		}
+	}
+
+	return formatted, diagnostics
+}
+
+// arguments gets the arguments filling the placeholders of a Term of a Context: the Data of the render, overridden by the Arguments and Count of the Context
+func (r *renderer) arguments(c *Context) map[string]string {
+	arguments := make(map[string]string)
+
+	for name, value := range r.data { // For each item of Data
+		arguments[name] = value
+	}
+
+	for name, value := range c.Arguments { // For each Argument of the Context
+		arguments[name] = value
+	}
+
+	if c.Count != "" { // The Count can also be used as a placeholder
+		arguments["count"] = c.Count
+	}
+
+	return arguments
+}
+
+// valueUsesPlaceholder returns whether any form of a Value has the placeholder, including placeholders nested in plural and select arguments
+func valueUsesPlaceholder(value Value, name string) bool {
+	for _, form := range value.forms() { // For each form
+		message, messageErr := ParseMessage(form)
+
+		if messageErr != nil { // Invalid forms are reported on their own
+			continue
+		}
+
+		for _, argument := range message.Arguments() {
+			if argument == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// renderFragment renders the Fragment of a Context to w, ensuring it doesn't create an include cycle or exceed the MaxIncludeDepth
+func (r *renderer) renderFragment(w io.Writer, c *Context) []ParseError {
+	fragmentFile, includeErrs := r.engine.includeFragment(c, r.includes)
+
+	if len(includeErrs) != 0 { // If the Fragment can't be imported
+		return includeErrs
+	}
+
+	diagnostics, renderErr := r.renderFile(w, fragmentFile) // Render the Fragment in place
+
+	if renderErr != nil && len(diagnostics) == 0 { // If the fragment file does not exist
+		return []ParseError{c.newParseError(ErrorFragmentRead, renderErr.Error())}
+	}
+
+	return diagnostics
+}
+
+// includeFragment resolves the Fragment of a Context, ensuring importing it from the files currently being parsed doesn't create an include cycle or exceed the MaxIncludeDepth
+func (e *Engine) includeFragment(c *Context, includes []string) (string, []ParseError) {
+	fragmentFile, resolveErr := e.resolveFragment(c.File, c.Source) // Fragments are relative to the file they are declared in
+
+	if resolveErr != nil { // If the Fragment resolves outside of the root
+		return "", []ParseError{c.newParseError(ErrorOutsideRoot, "Cannot import "+c.Source+": "+resolveErr.Error())}
+	}
+
+	if len(includes) == 0 { // If the Context is being parsed on its own, its file is the only include
+		currentFile, _ := e.includePath(c.File)
+		includes = []string{currentFile}
+	}
+
+	for index, include := range includes { // For each file currently being parsed
+		if include != fragmentFile { // Not a cycle
+			continue
+		}
+
+		if index == len(includes)-1 { // If we're attempting Fragment inception
+			return "", []ParseError{c.newParseError(ErrorSelfImport, "Cannot import "+c.Source+" within itself.")}
+		}
+
+		chain := append(append([]string{}, includes[index:]...), fragmentFile) // The full chain of includes, from the first occurrence back to itself
+
+		for chainIndex, chainFile := range chain { // For each file in the chain, use a path relative to the root for readability
+			chain[chainIndex] = e.displayPath(chainFile)
+		}
+
+		return "", []ParseError{c.newParseError(ErrorIncludeCycle, "Include cycle: "+strings.Join(chain, " → "))}
+	}
+
+	if len(includes) > e.maxIncludeDepth() { // If importing this Fragment would exceed the maximum depth
+		return "", []ParseError{c.newParseError(ErrorIncludeDepth, "Cannot import "+c.Source+": exceeds maximum include depth of "+strconv.Itoa(e.maxIncludeDepth()))}
+	}
+
+	return fragmentFile, nil
+}
+
+// maxIncludeDepth gets the maximum number of nested Fragments
+func (e *Engine) maxIncludeDepth() int {
+	if e.MaxIncludeDepth > 0 { // If a limit was configured
+		return e.MaxIncludeDepth
+	}
+
+	return DefaultMaxIncludeDepth
+}
+
+// newParseError creates a ParseError for the Node of this Context
+func (c *Context) newParseError(kind ErrorKind, message string) ParseError {
+	return newParseError(c.File, c.Node, kind, message)
 }
@@ -0,0 +1,262 @@
+// This file contains the tests of parsing templates into content and Diagnostics
+
+package frala
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseDiagnostics ensures each problem is reported as a Diagnostic of its kind at its position, and fails the parse only when Strict
+func TestParseDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		content  string
+		kind     ErrorKind
+		position string
+	}{
+		{"missing source", `<p>{{ type="term" }}</p>`, "<p></p>", ErrorMissingSource, "1:4"},
+		{"missing type", "<p>\n{{ src=\"hello\" }}</p>", "<p>\n</p>", ErrorMissingType, "2:1"},
+		{"invalid type", `{{ type="image" src="hello" }}`, "", ErrorInvalidType, "1:1"},
+		{"untranslated", `<p>{{ type="term" src="bye" }}</p>`, "<p></p>", ErrorUntranslated, "1:4"},
+		{"missing fragment", `a {{ type="fragment" src="missing.html" }}`, "a ", ErrorFragmentRead, "1:3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello", "fi": "Hei"}, "bye": {"de": "Tschüss"}}}`, map[string]string{
+				"page.html": test.template,
+			})
+
+			response := engine.ParseWith(filepath.Join(dir, "page.html"), RenderOptions{Language: "fi"})
+
+			if response.Error != nil || response.Content != test.content {
+				t.Errorf("ParseWith = %q, %v, want %q", response.Content, response.Error, test.content)
+			}
+
+			if len(response.Diagnostics) != 1 {
+				t.Fatalf("Diagnostics = %v, want one", response.Diagnostics)
+			}
+
+			diagnostic := response.Diagnostics[0]
+
+			if position := (Position{Line: diagnostic.Line, Column: diagnostic.Column}).String(); diagnostic.Kind != test.kind || position != test.position || diagnostic.File != filepath.Join(dir, "page.html") {
+				t.Errorf("Diagnostic = %s at %s (%v), want %s at %s", diagnostic.Kind, position, diagnostic.Error(), test.kind, test.position)
+			}
+
+			if !strings.HasPrefix(diagnostic.Tag, "{{") {
+				t.Errorf("Tag = %q, want the Frala syntax", diagnostic.Tag)
+			}
+
+			strict := engine.ParseWith(filepath.Join(dir, "page.html"), RenderOptions{Language: "fi", Strict: true})
+
+			if strict.Error == nil || strict.Content != "" || len(strict.Diagnostics) != 1 {
+				t.Errorf("Strict ParseWith = %q, %v, %v, want an error and no content", strict.Content, strict.Diagnostics, strict.Error)
+			}
+		})
+	}
+}
+
+// TestParseStrictConfig ensures Strict in the Config fails a parse with any Diagnostic, as Strict in the RenderOptions does
+func TestParseStrictConfig(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Strict": true, "Terms": {"hello": {"en": "Hello"}}}`, map[string]string{
+		"good.html": `{{ type="term" src="hello" }}`,
+		"bad.html":  `{{ type="term" }}`,
+	})
+
+	if response := engine.Parse(filepath.Join(dir, "good.html")); response.Error != nil || response.Content != "Hello" {
+		t.Errorf("Parse(good.html) = %q, %v, want Hello", response.Content, response.Error)
+	}
+
+	if response := engine.Parse(filepath.Join(dir, "bad.html")); response.Error == nil || response.Content != "" {
+		t.Errorf("Parse(bad.html) = %q, %v, want an error", response.Content, response.Error)
+	}
+}
+
+// TestEnginesIndependent ensures Engines each use their own Config and Terms
+func TestEnginesIndependent(t *testing.T) {
+	first, firstDir := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hello"}}}`, map[string]string{"page.html": `{{ type="term" src="hello" }}`})
+	second, secondDir := newTestEngine(t, `{"DefaultLanguage": "fi", "Terms": {"hello": {"fi": "Hei"}}}`, map[string]string{"page.html": `{{ type="term" src="hello" }}`})
+
+	second.SetValue("hello", "fi", "Moi")
+
+	if response := first.Parse(filepath.Join(firstDir, "page.html")); response.Content != "Hello" {
+		t.Errorf("first Parse = %q, want Hello", response.Content)
+	}
+
+	if response := second.Parse(filepath.Join(secondDir, "page.html")); response.Content != "Moi" {
+		t.Errorf("second Parse = %q, want Moi", response.Content)
+	}
+
+	if _, exists := first.Config.Terms["hello"]["fi"]; exists {
+		t.Errorf("setting a value of the second Engine changed the first")
+	}
+
+	if _, newErr := New(Options{ConfigFile: filepath.Join(firstDir, "missing.json")}); newErr == nil {
+		t.Errorf("New with a missing config file succeeded")
+	}
+}
+
+// TestMultilingualParse ensures every file is parsed in every language, in the order of the files, whatever the Parallelism
+func TestMultilingualParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		languages   string
+		parallelism int
+		want        map[string][]string // Content of each HTML file, by language
+	}{
+		{"sequential", `["en", "fi", "pt_BR"]`, 1, map[string][]string{"en": {"1 Hello", "2 Hello"}, "fi": {"1 Hei", "2 Hei"}, "pt-BR": {"1 Olá", "2 Olá"}}},
+		{"concurrent", `["en", "fi", "pt-BR"]`, 8, map[string][]string{"en": {"1 Hello", "2 Hello"}, "fi": {"1 Hei", "2 Hei"}, "pt-BR": {"1 Olá", "2 Olá"}}},
+		{"default language only", `[]`, 0, map[string][]string{"en": {"1 Hello", "2 Hello"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": `+test.languages+`, "Terms": {"hello": {"en": "Hello", "fi": "Hei", "pt-BR": "Olá"}}}`, map[string]string{
+				"1.html": `1 {{ type="term" src="hello" }}`,
+				"2.html": `2 {{ type="term" src="hello" }}`,
+			})
+
+			engine.Parallelism = test.parallelism
+			responses := engine.MultilingualParse([]string{filepath.Join(dir, "1.html"), filepath.Join(dir, "style.css"), filepath.Join(dir, "2.html")}) // Only HTML files are parsed
+
+			if len(responses) != len(test.want) {
+				t.Fatalf("MultilingualParse languages = %d, want %d", len(responses), len(test.want))
+			}
+
+			for language, want := range test.want {
+				var contents []string
+
+				for _, response := range responses[language] {
+					if response.Language != language || response.Error != nil {
+						t.Errorf("response %s = %s, %v, want %s", response.Name, response.Language, response.Error, language)
+					}
+
+					contents = append(contents, response.Content)
+				}
+
+				if strings.Join(contents, "|") != strings.Join(want, "|") {
+					t.Errorf("%s = %q, want %q", language, contents, want)
+				}
+			}
+		})
+	}
+}
+
+// TestIncludeCycles ensures Fragments importing themselves, directly or through other Fragments, and Fragments nested too deeply are reported rather than followed
+func TestIncludeCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		maxDepth int
+		content  string
+		kind     ErrorKind
+		message  string
+	}{
+		{
+			name:    "self import",
+			files:   map[string]string{"page.html": `a{{ type="fragment" src="page.html" }}`},
+			content: "a",
+			kind:    ErrorSelfImport,
+		},
+		{
+			name: "cycle of two",
+			files: map[string]string{
+				"page.html": `a{{ type="fragment" src="b.html" }}`,
+				"b.html":    `b{{ type="fragment" src="page.html" }}`,
+			},
+			content: "ab",
+			kind:    ErrorIncludeCycle,
+			message: "Include cycle: page.html → b.html → page.html",
+		},
+		{
+			name: "cycle of three below the page",
+			files: map[string]string{
+				"page.html":    `a{{ type="fragment" src="parts/b.html" }}`,
+				"parts/b.html": `b{{ type="fragment" src="c.html" }}`,
+				"parts/c.html": `c{{ type="fragment" src="d.html" }}`,
+				"parts/d.html": `d{{ type="fragment" src="b.html" }}`,
+			},
+			content: "abcd",
+			kind:    ErrorIncludeCycle,
+			message: "Include cycle: parts/b.html → parts/c.html → parts/d.html → parts/b.html",
+		},
+		{
+			name: "depth",
+			files: map[string]string{
+				"page.html": `a{{ type="fragment" src="b.html" }}`,
+				"b.html":    `b{{ type="fragment" src="c.html" }}`,
+				"c.html":    `c{{ type="fragment" src="d.html" }}`,
+				"d.html":    `d`,
+			},
+			maxDepth: 2,
+			content:  "abc",
+			kind:     ErrorIncludeDepth,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, dir := newTestEngine(t, `{"DefaultLanguage": "en"}`, test.files)
+			engine.MaxIncludeDepth = test.maxDepth
+			response := engine.Parse(filepath.Join(dir, "page.html"))
+
+			if response.Content != test.content || response.Error != nil {
+				t.Errorf("Parse = %q, %v, want %q", response.Content, response.Error, test.content)
+			}
+
+			if len(response.Diagnostics) != 1 || response.Diagnostics[0].Kind != test.kind {
+				t.Fatalf("Diagnostics = %v, want one of %s", response.Diagnostics, test.kind)
+			}
+
+			if test.message != "" && !strings.Contains(response.Diagnostics[0].Message, test.message) {
+				t.Errorf("Message = %q, want %q", response.Diagnostics[0].Message, test.message)
+			}
+		})
+	}
+}
+
+// TestIncludeSharedFragment ensures a Fragment imported more than once, without a cycle, is imported each time
+func TestIncludeSharedFragment(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en"}`, map[string]string{
+		"page.html":   `{{ type="fragment" src="b.html" }}{{ type="fragment" src="c.html" }}`,
+		"b.html":      `b{{ type="fragment" src="shared.html" }}`,
+		"c.html":      `c{{ type="fragment" src="shared.html" }}`,
+		"shared.html": `s`,
+	})
+
+	if response := engine.Parse(filepath.Join(dir, "page.html")); response.Content != "bscs" || len(response.Diagnostics) != 0 {
+		t.Errorf("Parse = %q, %v, want bscs", response.Content, response.Diagnostics)
+	}
+}
+
+// initConfig and initEngine are the package-level Config and default Engine after Frala was initialized, before any test could use them
+var initConfig ConfigOptions
+var initEngine *Engine
+
+func init() {
+	initConfig, initEngine = Config, defaultEngine // Runs after the init of frala.go, as files are initialized in order of their names
+}
+
+// TestDefaultInitialized ensures the default Engine, its Config and InitError are set when Frala is initialized, rather than on first use
+func TestDefaultInitialized(t *testing.T) {
+	if initEngine == nil || initEngine.Config != &Config {
+		t.Fatalf("Default Engine after initialization = %v, want one using Config", initEngine)
+	}
+
+	if initConfig.DefaultLanguage != "en" || initConfig.CurrentLanguage != "en" || initConfig.Direction != "ltr" {
+		t.Errorf("Config after initialization = %+v, want the defaults", initConfig)
+	}
+
+	if !errors.Is(InitError, fs.ErrNotExist) { // The working directory of the tests has no frala.json
+		t.Errorf("InitError = %v, want the missing frala.json", InitError)
+	}
+
+	if Default() != initEngine {
+		t.Errorf("Default created another Engine")
+	}
+}
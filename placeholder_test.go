@@ -0,0 +1,54 @@
+// This file contains the tests of filling named placeholders in Term values
+
+package frala
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPlaceholders ensures placeholders are filled from attributes before Data, and missing or unused ones are reported
+func TestPlaceholders(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {
+		"welcome": {"en": "Welcome, {name}!"},
+		"pair": {"en": "{a} and {b}"},
+		"plain": {"en": "Plain"}
+	}}`, nil)
+
+	tests := []struct {
+		name     string
+		template string
+		data     map[string]string
+		content  string
+		kinds    []ErrorKind
+	}{
+		{"attribute", `{{ type="term" src="welcome" name="Joshua" }}`, nil, "Welcome, Joshua!", nil},
+		{"data", `{{ type="term" src="welcome" }}`, map[string]string{"name": "Data"}, "Welcome, Data!", nil},
+		{"attribute over data", `{{ type="term" src="welcome" name="Attribute" }}`, map[string]string{"name": "Data"}, "Welcome, Attribute!", nil},
+		{"several", `{{ type="term" src="pair" a="1" b="2" }}`, nil, "1 and 2", nil},
+		{"missing", `{{ type="term" src="welcome" }}`, nil, "Welcome, {name}!", []ErrorKind{ErrorPlaceholderMissing}},
+		{"unused", `{{ type="term" src="plain" name="Joshua" }}`, nil, "Plain", []ErrorKind{ErrorPlaceholderUnused}},
+		{"unused data", `{{ type="term" src="plain" }}`, map[string]string{"name": "Data"}, "Plain", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var content strings.Builder
+			diagnostics, renderErr := engine.RenderReader(&content, "page.html", strings.NewReader(test.template), RenderOptions{Data: test.data})
+
+			if renderErr != nil || content.String() != test.content {
+				t.Errorf("RenderReader = %q, %v, want %q", content.String(), renderErr, test.content)
+			}
+
+			var kinds []ErrorKind
+
+			for _, diagnostic := range diagnostics {
+				kinds = append(kinds, diagnostic.Kind)
+			}
+
+			if len(kinds) != len(test.kinds) || (len(kinds) != 0 && kinds[0] != test.kinds[0]) {
+				t.Errorf("Diagnostics = %v, want %v", diagnostics, test.kinds)
+			}
+		})
+	}
+}
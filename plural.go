@@ -0,0 +1,452 @@
+// This file contains functionality for choosing the CLDR plural category of a count
+// The rules are those of CLDR 42, including the many category French, Spanish, Italian, Catalan and Portuguese use for millions.
+
+package frala
+
+import (
+	"errors"
+	"strings"
+)
+
+// The CLDR plural categories. Every language uses PluralOther.
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// pluralOperands are the CLDR plural operands of a count
+type pluralOperands struct {
+	i     int64 // Integer digits of n
+	v     int   // Number of visible fraction digits of n, with trailing zeros
+	f     int64 // Visible fraction digits of n, with trailing zeros
+	t     int64 // Visible fraction digits of n, without trailing zeros
+	e     int   // Exponent of the compact decimal notation of n, such as 6 for 1.2c6
+	isInt bool  // Whether n is an integer, where conditions on n can use i
+}
+
+// pluralRule is the plural rule of a language, with the categories it uses
+type pluralRule struct {
+	categories []string                      // Categories the language uses, in CLDR order
+	category   func(o pluralOperands) string // Category returns the category of the operands
+}
+
+// newPluralOperands parses a count such as 1, -5, 1.50 or 1.2c6 (1200000 in compact decimal notation) into its plural operands
+func newPluralOperands(count string) (pluralOperands, error) {
+	var o pluralOperands
+
+	count = strings.TrimPrefix(strings.TrimSpace(count), "-") // The sign of a count does not affect its category
+	decimal, exponent, hasExponent := strings.Cut(strings.Replace(count, "e", "c", 1), "c")
+	integer, fraction, hasFraction := strings.Cut(decimal, ".")
+
+	if integer == "" || !isDigits(integer) || (hasFraction && (fraction == "" || !isDigits(fraction))) || (hasExponent && (exponent == "" || len(exponent) > 2 || !isDigits(exponent))) { // If this isn't a decimal number
+		return o, errors.New("Count " + count + " is not a number")
+	}
+
+	if hasExponent { // Move the decimal point by the exponent, so 1.2c6 has the operands of 1200000
+		o.e = int(parseDigits(exponent))
+
+		if len(fraction) < o.e { // Pad the fraction with zeros, so 1c6 moves the decimal point by six digits
+			fraction += strings.Repeat("0", o.e-len(fraction))
+		}
+
+		integer, fraction = integer+fraction[:o.e], fraction[o.e:]
+	}
+
+	o.i = parseDigits(integer)
+	o.v = len(fraction)
+	o.f = parseDigits(fraction)
+	o.t = parseDigits(strings.TrimRight(fraction, "0"))
+	o.isInt = o.f == 0
+
+	return o, nil
+}
+
+// isDigits returns whether the string is only ASCII digits
+func isDigits(s string) bool {
+	for _, c := range s { // For each character
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseDigits parses ASCII digits, keeping only the last 18 so large counts cannot overflow. No CLDR rule uses more than the last 6
+func parseDigits(digits string) int64 {
+	var value int64
+
+	if len(digits) > 18 {
+		digits = digits[len(digits)-18:]
+	}
+
+	for _, c := range digits { // For each digit
+		value = value*10 + int64(c-'0')
+	}
+
+	return value
+}
+
+// n returns whether n is an integer equal to one of the values provided
+func (o pluralOperands) n(values ...int64) bool {
+	if !o.isInt {
+		return false
+	}
+
+	for _, value := range values { // For each value
+		if o.i == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMillions returns whether n is a multiple of a million, for the many category of the Romance languages
+func (o pluralOperands) isMillions() bool {
+	return (o.e == 0 && o.i != 0 && o.i%1000000 == 0 && o.v == 0) || o.e > 5
+}
+
+// inRange returns whether value is within the inclusive range
+func inRange(value, from, to int64) bool {
+	return value >= from && value <= to
+}
+
+// pluralRules are the CLDR cardinal plural rules, keyed by language
+var pluralRules = map[string]pluralRule{}
+
+// registerPluralRule registers a plural rule for each of the languages provided
+func registerPluralRule(languages string, categories []string, category func(o pluralOperands) string) {
+	for _, language := range strings.Split(languages, ",") { // For each language
+		pluralRules[language] = pluralRule{categories: categories, category: category}
+	}
+}
+
+func init() {
+	oneOther := []string{PluralOne, PluralOther}
+	oneFewManyOther := []string{PluralOne, PluralFew, PluralMany, PluralOther}
+	oneFewOther := []string{PluralOne, PluralFew, PluralOther}
+	oneManyOther := []string{PluralOne, PluralMany, PluralOther}
+
+	registerPluralRule("bo,dz,id,ig,ja,jv,km,ko,lo,ms,my,sah,ses,sg,th,to,vi,wo,yo,yue,zh", []string{PluralOther}, func(o pluralOperands) string {
+		return PluralOther
+	})
+
+	registerPluralRule("ast,de,en,et,fi,fy,gl,ia,io,lij,nl,sc,sv,sw,ur,yi", oneOther, func(o pluralOperands) string {
+		if o.i == 1 && o.v == 0 {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("af,az,bg,el,eo,eu,ha,hu,ka,kk,ky,lb,ml,mn,nb,ne,nn,no,om,or,ps,sd,so,sq,ta,te,tk,tr,ug,uz", oneOther, func(o pluralOperands) string {
+		if o.n(1) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ff,hy,kab", oneOther, func(o pluralOperands) string {
+		if o.i == 0 || o.i == 1 {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("fr,pt", oneManyOther, func(o pluralOperands) string {
+		switch {
+		case o.i == 0 || o.i == 1:
+			return PluralOne
+		case o.isMillions():
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ca,it", oneManyOther, func(o pluralOperands) string {
+		switch {
+		case o.i == 1 && o.v == 0:
+			return PluralOne
+		case o.isMillions():
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("es", oneManyOther, func(o pluralOperands) string {
+		switch {
+		case o.n(1):
+			return PluralOne
+		case o.isMillions():
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("am,as,bn,doi,fa,gu,hi,kn,pcm,zu", oneOther, func(o pluralOperands) string {
+		if o.i == 0 || o.n(1) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("da", oneOther, func(o pluralOperands) string {
+		if o.n(1) || (o.t != 0 && (o.i == 0 || o.i == 1)) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("is", oneOther, func(o pluralOperands) string {
+		if (o.t == 0 && o.i%10 == 1 && o.i%100 != 11) || (o.t%10 == 1 && o.t%100 != 11) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("mk", oneOther, func(o pluralOperands) string {
+		if (o.v == 0 && o.i%10 == 1 && o.i%100 != 11) || (o.f%10 == 1 && o.f%100 != 11) {
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ru,uk", oneFewManyOther, func(o pluralOperands) string {
+		switch {
+		case o.v == 0 && o.i%10 == 1 && o.i%100 != 11:
+			return PluralOne
+		case o.v == 0 && inRange(o.i%10, 2, 4) && !inRange(o.i%100, 12, 14):
+			return PluralFew
+		case o.v == 0 && (o.i%10 == 0 || inRange(o.i%10, 5, 9) || inRange(o.i%100, 11, 14)):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("be", oneFewManyOther, func(o pluralOperands) string {
+		switch {
+		case o.isInt && o.i%10 == 1 && o.i%100 != 11:
+			return PluralOne
+		case o.isInt && inRange(o.i%10, 2, 4) && !inRange(o.i%100, 12, 14):
+			return PluralFew
+		case o.isInt && (o.i%10 == 0 || inRange(o.i%10, 5, 9) || inRange(o.i%100, 11, 14)):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("pl", oneFewManyOther, func(o pluralOperands) string {
+		switch {
+		case o.i == 1 && o.v == 0:
+			return PluralOne
+		case o.v == 0 && inRange(o.i%10, 2, 4) && !inRange(o.i%100, 12, 14):
+			return PluralFew
+		case o.v == 0 && ((o.i != 1 && inRange(o.i%10, 0, 1)) || inRange(o.i%10, 5, 9) || inRange(o.i%100, 12, 14)):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("cs,sk", oneFewManyOther, func(o pluralOperands) string {
+		switch {
+		case o.i == 1 && o.v == 0:
+			return PluralOne
+		case inRange(o.i, 2, 4) && o.v == 0:
+			return PluralFew
+		case o.v != 0:
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("bs,hr,sh,sr", oneFewOther, func(o pluralOperands) string {
+		switch {
+		case (o.v == 0 && o.i%10 == 1 && o.i%100 != 11) || (o.f%10 == 1 && o.f%100 != 11):
+			return PluralOne
+		case (o.v == 0 && inRange(o.i%10, 2, 4) && !inRange(o.i%100, 12, 14)) || (inRange(o.f%10, 2, 4) && !inRange(o.f%100, 12, 14)):
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ro,mo", oneFewOther, func(o pluralOperands) string {
+		switch {
+		case o.i == 1 && o.v == 0:
+			return PluralOne
+		case o.v != 0 || o.n(0) || (o.isInt && o.i != 1 && inRange(o.i%100, 1, 19)):
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("sl", []string{PluralOne, PluralTwo, PluralFew, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.v == 0 && o.i%100 == 1:
+			return PluralOne
+		case o.v == 0 && o.i%100 == 2:
+			return PluralTwo
+		case (o.v == 0 && inRange(o.i%100, 3, 4)) || o.v != 0:
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("lt", oneFewManyOther, func(o pluralOperands) string {
+		switch {
+		case o.isInt && o.i%10 == 1 && !inRange(o.i%100, 11, 19):
+			return PluralOne
+		case o.isInt && inRange(o.i%10, 2, 9) && !inRange(o.i%100, 11, 19):
+			return PluralFew
+		case o.f != 0:
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("lv", []string{PluralZero, PluralOne, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case (o.isInt && (o.i%10 == 0 || inRange(o.i%100, 11, 19))) || (o.v == 2 && inRange(o.f%100, 11, 19)):
+			return PluralZero
+		case (o.isInt && o.i%10 == 1 && o.i%100 != 11) || (o.v == 2 && o.f%10 == 1 && o.f%100 != 11) || (o.v != 2 && o.f%10 == 1):
+			return PluralOne
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("he,iw", []string{PluralOne, PluralTwo, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case (o.i == 1 && o.v == 0) || (o.i == 0 && o.v != 0):
+			return PluralOne
+		case o.i == 2 && o.v == 0:
+			return PluralTwo
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ar,ars", []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(0):
+			return PluralZero
+		case o.n(1):
+			return PluralOne
+		case o.n(2):
+			return PluralTwo
+		case o.isInt && inRange(o.i%100, 3, 10):
+			return PluralFew
+		case o.isInt && inRange(o.i%100, 11, 99):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("ga", []string{PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1):
+			return PluralOne
+		case o.n(2):
+			return PluralTwo
+		case o.n(3, 4, 5, 6):
+			return PluralFew
+		case o.n(7, 8, 9, 10):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("gd", []string{PluralOne, PluralTwo, PluralFew, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(1, 11):
+			return PluralOne
+		case o.n(2, 12):
+			return PluralTwo
+		case o.isInt && (inRange(o.i, 3, 10) || inRange(o.i, 13, 19)):
+			return PluralFew
+		}
+
+		return PluralOther
+	})
+
+	registerPluralRule("cy", []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}, func(o pluralOperands) string {
+		switch {
+		case o.n(0):
+			return PluralZero
+		case o.n(1):
+			return PluralOne
+		case o.n(2):
+			return PluralTwo
+		case o.n(3):
+			return PluralFew
+		case o.n(6):
+			return PluralMany
+		}
+
+		return PluralOther
+	})
+}
+
+// pluralRuleOf gets the plural rule of a language, and whether Frala has a built-in rule for it
+// Languages without a built-in rule only use PluralOther.
+func pluralRuleOf(language string) (pluralRule, bool) {
+	tag, _ := ParseTag(language)
+
+	if tag.Language == "pt" && tag.Region == "PT" { // European Portuguese differs from pt
+		return pluralRules["it"], true
+	}
+
+	if rule, exists := pluralRules[tag.Language]; exists {
+		return rule, true
+	}
+
+	return pluralRules["ja"], false
+}
+
+// PluralCategory gets the CLDR plural category of a count in a language, such as "one" for 1 in en
+// Returns PluralOther for languages without a built-in rule, and an error if the count is not a number.
+func PluralCategory(language, count string) (string, error) {
+	operands, operandsErr := newPluralOperands(count)
+
+	if operandsErr != nil {
+		return PluralOther, operandsErr
+	}
+
+	rule, _ := pluralRuleOf(language)
+	return rule.category(operands), nil
+}
+
+// PluralCategories gets the CLDR plural categories a language uses, always ending with PluralOther
+func PluralCategories(language string) []string {
+	rule, _ := pluralRuleOf(language)
+	return append([]string{}, rule.categories...)
+}
+
+// HasPluralRule returns whether Frala has a built-in plural rule for a language
+func HasPluralRule(language string) bool {
+	_, exists := pluralRuleOf(language)
+	return exists
+}
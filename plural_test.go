@@ -0,0 +1,123 @@
+// This file contains the tests of the CLDR plural rules
+
+package frala
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPluralCategory ensures counts get the CLDR plural category of each rule, from the samples of CLDR
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		language string
+		counts   map[string]string // Category expected for each count
+	}{
+		{"en", map[string]string{"1": "one", "0": "other", "2": "other", "1.0": "other", "-1": "one", "1000000": "other"}},
+		{"ja", map[string]string{"1": "other", "0": "other"}},
+		{"fr", map[string]string{"0": "one", "1": "one", "1.5": "one", "2": "other", "1000000": "many", "2000000": "many", "1000001": "other", "1c6": "many", "1.2c6": "many", "1.5c3": "other", "1000000.5": "other"}},
+		{"es", map[string]string{"1": "one", "0": "other", "1.0": "one", "1000000": "many", "1c3": "other"}},
+		{"it", map[string]string{"1": "one", "1.0": "other", "2": "other", "1000000": "many"}},
+		{"ca", map[string]string{"1": "one", "1000000": "many", "999999": "other"}},
+		{"pt", map[string]string{"0": "one", "1": "one", "1.5": "one", "2": "other", "1000000": "many"}},
+		{"pt-PT", map[string]string{"0": "other", "1": "one", "1.5": "other", "1000000": "many"}},
+		{"pt_BR.UTF-8", map[string]string{"0": "one"}},
+		{"hi", map[string]string{"0": "one", "1": "one", "0.5": "one", "2": "other"}},
+		{"da", map[string]string{"1": "one", "0.1": "one", "2": "other"}},
+		{"ru", map[string]string{"1": "one", "21": "one", "11": "many", "2": "few", "24": "few", "12": "many", "5": "many", "100": "many", "1.5": "other"}},
+		{"pl", map[string]string{"1": "one", "2": "few", "22": "few", "12": "many", "5": "many", "21": "many", "1.5": "other"}},
+		{"cs", map[string]string{"1": "one", "3": "few", "5": "other", "1.5": "many"}},
+		{"hr", map[string]string{"1": "one", "21": "one", "2": "few", "5": "other", "0.1": "one", "0.2": "few"}},
+		{"ro", map[string]string{"1": "one", "0": "few", "19": "few", "20": "other", "101": "few", "1.5": "few"}},
+		{"sl", map[string]string{"1": "one", "101": "one", "2": "two", "3": "few", "5": "other", "1.5": "few"}},
+		{"lt", map[string]string{"1": "one", "2": "few", "11": "other", "0.5": "many"}},
+		{"lv", map[string]string{"0": "zero", "10": "zero", "1": "one", "21": "one", "2": "other"}},
+		{"he", map[string]string{"1": "one", "2": "two", "3": "other", "0.5": "one"}},
+		{"ar", map[string]string{"0": "zero", "1": "one", "2": "two", "3": "few", "110": "few", "11": "many", "100": "other", "1.5": "other"}},
+		{"ga", map[string]string{"1": "one", "2": "two", "3": "few", "7": "many", "11": "other"}},
+		{"cy", map[string]string{"0": "zero", "1": "one", "2": "two", "3": "few", "6": "many", "4": "other"}},
+		{"xx", map[string]string{"1": "other"}}, // Without a built-in rule
+	}
+
+	for _, test := range tests {
+		for count, want := range test.counts {
+			if got, categoryErr := PluralCategory(test.language, count); categoryErr != nil || got != want {
+				t.Errorf("PluralCategory(%s, %s) = %s, %v, want %s", test.language, count, got, categoryErr, want)
+			}
+		}
+	}
+}
+
+// TestPluralCategoryInvalid ensures counts which aren't numbers are rejected
+func TestPluralCategoryInvalid(t *testing.T) {
+	for _, count := range []string{"", "one", "1.", ".5", "1,5", "1c", "1c100", "1e-3", "--1"} {
+		if category, categoryErr := PluralCategory("en", count); categoryErr == nil || category != PluralOther {
+			t.Errorf("PluralCategory(en, %q) = %s, %v, want an error", count, category, categoryErr)
+		}
+	}
+}
+
+// TestPluralCategories ensures each language lists the categories its rule uses
+func TestPluralCategories(t *testing.T) {
+	tests := map[string][]string{
+		"en":    {"one", "other"},
+		"fr":    {"one", "many", "other"},
+		"pt-PT": {"one", "many", "other"},
+		"ru":    {"one", "few", "many", "other"},
+		"ar":    {"zero", "one", "two", "few", "many", "other"},
+		"ja":    {"other"},
+		"xx":    {"other"},
+	}
+
+	for language, want := range tests {
+		if got := PluralCategories(language); !reflect.DeepEqual(got, want) {
+			t.Errorf("PluralCategories(%s) = %v, want %v", language, got, want)
+		}
+	}
+
+	if HasPluralRule("xx") || !HasPluralRule("fr-CA") {
+		t.Errorf("HasPluralRule(xx) = %v, HasPluralRule(fr-CA) = %v", HasPluralRule("xx"), HasPluralRule("fr-CA"))
+	}
+}
+
+// TestPluralTerm ensures the count of a term chooses its plural form in the language being parsed
+func TestPluralTerm(t *testing.T) {
+	engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "ru"], "Terms": {"files": {
+		"en": {"one": "{count} file", "other": "{count} files"},
+		"ru": {"one": "{count} файл", "few": "{count} файла", "many": "{count} файлов", "other": "{count} файла"}
+	}}}`, nil)
+
+	tests := []struct {
+		language string
+		count    string
+		content  string
+		kind     ErrorKind // Kind of the Diagnostic, if any
+	}{
+		{"en", "1", "1 file", 0},
+		{"en", "5", "5 files", 0},
+		{"ru", "1", "1 файл", 0},
+		{"ru", "3", "3 файла", 0},
+		{"ru", "11", "11 файлов", 0},
+		{"ru", "1.5", "1.5 файла", 0},
+		{"en", "many", "many files", ErrorPlural},
+	}
+
+	for _, test := range tests {
+		response := engine.ParseReader("page.html", strings.NewReader(`{{ type="term" src="files" lang="`+test.language+`" count="`+test.count+`" }}`))
+
+		if response.Content != test.content {
+			t.Errorf("%s count %s = %q, want %q", test.language, test.count, response.Content, test.content)
+		}
+
+		if test.kind == 0 && len(response.Diagnostics) != 0 {
+			t.Errorf("%s count %s Diagnostics = %v, want none", test.language, test.count, response.Diagnostics)
+		} else if test.kind != 0 && (len(response.Diagnostics) != 1 || response.Diagnostics[0].Kind != test.kind) {
+			t.Errorf("%s count %s Diagnostics = %v, want one of %s", test.language, test.count, response.Diagnostics, test.kind)
+		}
+	}
+
+	if response := engine.ParseReader("page.html", strings.NewReader(`{{ type="term" src="files" }}`)); len(response.Diagnostics) == 0 || response.Diagnostics[0].Kind != ErrorPlural { // Without a count
+		t.Errorf("Diagnostics without a count = %v, want one of %s", response.Diagnostics, ErrorPlural)
+	}
+}
@@ -3,40 +3,383 @@
 package frala
 
 import (
-	"github.com/robfig/gettext-go/gettext/po" // Support for reading / writing GNU PO files
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 )
 
-// ConvertFromPo reads a .po file and convert its content to Frala Terms, automatically adding them to the config
+// ConvertFromPo reads a .po file and convert its content to Frala Terms, automatically adding them to the config of the default Engine
 func ConvertFromPo(fileName string) error {
-	var conversionError error
-	var poFile *po.File
-	poFile, conversionError = po.Load(fileName)
-
-	if conversionError == nil { // If there was no issue loading the po file
-		for _, message := range poFile.Messages { // For each po.Message struc in poFile.Messages
-			poLanguage := Sanitize(poFile.MimeHeader.Language)  // Ensure the Po file's MimeHeader Language is sanitized
-			SetValue(message.MsgId, poLanguage, message.MsgStr) // Set the msg ID / val as term / value for the language of the file
-
-			if !strings.Contains(strings.Join(Config.Languages, ",")+",", poLanguage+",") { // If the Languages array doesn't contain this Po file lang
-				Config.Languages = append(Config.Languages, poLanguage) // Append the language of the Po file to the Languages
-				sort.Strings(Config.Languages)                          // Sort the Languages
+	return Default().ConvertFromPo(fileName)
+}
+
+// ConvertToPo converts Frala Terms of the default Engine into msgid / msgstr context for usage in a .po file
+func ConvertToPo(language string) string {
+	return Default().ConvertToPo(language)
+}
+
+// PoMerge is the result of merging the Terms of the Config into a Po file
+type PoMerge struct {
+	Content  string   // Content of the merged Po file
+	Added    []string // Added are the Terms added to the Po file
+	Fuzzy    []string // Fuzzy are the Terms flagged as fuzzy, since the value of their DefaultLanguage changed
+	Obsolete []string // Obsolete are the entries moved to obsolete #~ entries, since their Term was removed
+}
+
+// ExtractPot scans templates, and the Fragments they import, for terms using the default Engine, returning a Po template (.pot) with an entry for each
+func ExtractPot(files []string) (string, error) {
+	return Default().ExtractPot(files)
+}
+
+// MergePo updates the content of a Po file with the Terms of the default Engine, keeping its translations
+func MergePo(content, language string) (PoMerge, error) {
+	return Default().MergePo(content, language)
+}
+
+// ConvertFromPo reads a .po file and convert its content to Frala Terms, automatically adding them to the config
+// Entries without a translation or flagged as fuzzy are skipped, so their Terms keep falling back to other languages until they are translated and reviewed.
+// Returns an error if the file could not be read, is not a valid Po file, or has no Language in its header.
+func (e *Engine) ConvertFromPo(fileName string) error {
+	content, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return readErr
+	}
+
+	entries, parseErr := parsePo(string(content))
+
+	if parseErr != nil {
+		return parseErr
+	}
+
+	poLanguage := ""
+
+	for _, entry := range entries { // Find the language declared by the header
+		if entry.MsgId == "" && !entry.Obsolete && !entry.Foreign {
+			poLanguage = entry.headerField("Language")
+			break
+		}
+	}
+
+	tag, tagErr := ParseTag(poLanguage) // Ensure the language of the Po file is canonical, such as pt-BR for pt_BR
+
+	if poLanguage == "" {
+		return errors.New("Po file has no Language in its header")
+	} else if tagErr != nil {
+		return tagErr
+	}
+
+	for _, entry := range entries { // For each entry which is a translated Term
+		if entry.MsgId == "" || entry.Obsolete || entry.Foreign || entry.hasFlag("fuzzy") {
+			continue
+		}
+
+		value := entry.value(tag.String())
+
+		e.termsLock.RLock()
+		existing := e.Config.Terms[entry.MsgId][tag.String()]
+		e.termsLock.RUnlock()
+
+		if value.IsPlural() { // If the entry has plural forms, which are only imported along with their other form
+			if validatePluralForms(value.Plural) == nil {
+				e.SetPluralValue(entry.MsgId, tag.String(), value.Plural)
 			}
+		} else if value.Text != "" && !existing.IsPlural() { // Plural forms are never replaced by a single form
+			e.SetValue(entry.MsgId, tag.String(), value.Text) // Set the msg ID / val as term / value for the language of the file
 		}
 	}
 
-	return conversionError
+	if !containsString(e.Config.Languages, tag.String()) { // If the Languages array doesn't contain this Po file lang
+		e.Config.Languages = append(e.Config.Languages, tag.String()) // Append the language of the Po file to the Languages
+		sort.Strings(e.Config.Languages)                              // Sort the Languages
+	}
+
+	return nil
 }
 
 // ConvertToPo converts Frala Terms into msgid / msgstr context for usage in a .po file
-func ConvertToPo(language string) string {
-	var poFile po.File
-	poFile.MimeHeader.Language = language // Language in MimeHeader as language provided
+// Terms which are not translated into the language have an empty msgstr, which ConvertFromPo skips. Terms with plural forms in the language, or in the
+// DefaultLanguage if they aren't translated, have a msgid_plural and a msgstr[n] for each plural category of the language, in the order of PluralCategories.
+func (e *Engine) ConvertToPo(language string) string {
+	poLanguage := language
+
+	if tag, tagErr := ParseTag(language); tagErr == nil { // PO files use POSIX locales, such as pt_BR
+		poLanguage = tag.POSIX()
+	}
+
+	var b strings.Builder
+	poHeader("Language: "+poLanguage, "MIME-Version: 1.0", "Content-Type: text/plain; charset=UTF-8", "Content-Transfer-Encoding: 8bit").write(&b)
+
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	termNames := make([]string, 0, len(e.Config.Terms))
+
+	for termName := range e.Config.Terms {
+		termNames = append(termNames, termName)
+	}
+
+	sort.Strings(termNames) // Write the entries in a consistent order
+
+	for _, termName := range termNames { // For each termName and term in Terms
+		entry := poEntry{MsgId: termName}
+		entry.setValue(CanonicalLanguage(language), e.Config.Terms[termName], e.Config.DefaultLanguage) // Only use this language, so untranslated Terms have an empty msgstr
+		entry.write(&b)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n") // Entries are separated by an empty line, without one after the last
+}
+
+// ExtractPot scans templates, and the Fragments they import, for terms, returning a Po template (.pot) with an entry for each
+// Unlike ConvertToPo, this includes terms which are not defined in the Config yet, and excludes Terms which no template uses. Each entry has a #: reference
+// to the file:line of every use of its term, relative to the root of the Engine, and the value of the DefaultLanguage as a comment if it is translated.
+// Returns an error if a template could not be read.
+func (e *Engine) ExtractPot(files []string) (string, error) {
+	l := newLinter(e)
+
+	if lintErr := l.lintFiles(files); lintErr != nil {
+		return "", lintErr
+	}
+
+	termNames := make([]string, 0, len(l.usages))
+
+	for termName := range l.usages {
+		termNames = append(termNames, termName)
+	}
+
+	sort.Strings(termNames)
+
+	var b strings.Builder
+	header := poHeader("MIME-Version: 1.0", "Content-Type: text/plain; charset=UTF-8", "Content-Transfer-Encoding: 8bit", "X-Generator: Frala")
+	header.Comments = []string{"Translation template of the terms used by the templates."}
+	header.write(&b)
+
+	for _, termName := range termNames { // For each term, in order
+		entry := poEntry{MsgId: termName}
+		seen := make(map[string]bool)
+
+		for _, usage := range l.usages[termName] { // For each use of the term, referenced once per line
+			file := usage.File
+
+			if includePath, pathErr := e.includePath(usage.File); pathErr == nil { // Use a path relative to the root, so the template is the same wherever it is extracted
+				file = e.displayPath(includePath)
+			}
+
+			if reference := filepath.ToSlash(file) + ":" + strconv.Itoa(usage.Node.Pos.Line); !seen[reference] {
+				seen[reference] = true
+				entry.References = append(entry.References, reference)
+			}
+		}
+
+		e.termsLock.RLock()
+		entry.Extracted = e.sourceComment(e.Config.Terms[termName])
+		e.termsLock.RUnlock()
+
+		entry.write(&b)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil // Entries are separated by an empty line, without one after the last
+}
+
+// MergePo updates the content of a Po file with the Terms of the Config, as msgmerge does with a Po template
+// Existing translations, comments and the header are kept. Terms which are not in the Po file are added, with their value in the language if they have one,
+// and entries whose Term was removed are moved to obsolete #~ entries. Each entry has the value of the DefaultLanguage as a #. comment, and translated entries are flagged as fuzzy when it changes.
+// Entries without the comment, such as those of ConvertToPo, are flagged as fuzzy when their #| previous msgid differs, or when their translation of the DefaultLanguage differs from the Config.
+// The language defaults to the Language of the header of the Po file. Entries with a msgctxt are kept as-is, since they aren't Terms.
+// Terms with plural forms have a msgstr[n] for each plural category of the language, as with ConvertToPo. Entries of Terms which gained or lost plural forms are reshaped with the value of the Config, and flagged as fuzzy if they were translated.
+func (e *Engine) MergePo(content, language string) (PoMerge, error) {
+	var merge PoMerge
+	entries, parseErr := parsePo(content)
+
+	if parseErr != nil {
+		return merge, parseErr
+	}
+
+	active := make(map[string]bool) // msgids of entries which are not obsolete
+
+	for _, entry := range entries {
+		if language == "" && entry.MsgId == "" && !entry.Obsolete && !entry.Foreign { // If this is the header, declaring the language
+			language = entry.headerField("Language")
+		}
 
-	for termName := range Config.Terms { // For each termName and term in Terms
-		poFile.Messages = append(poFile.Messages, po.Message{MsgId: termName, MsgStr: GetValue(termName, language)}) // Append a new po.Message
+		if !entry.Obsolete {
+			active[entry.MsgId] = true
+		}
+	}
+
+	if language == "" { // If the header has no Language
+		return merge, errors.New("Po file has no Language in its header")
+	}
+
+	tag, tagErr := ParseTag(language) // Po files use POSIX locales, such as pt_BR
+
+	if tagErr != nil {
+		return merge, tagErr
+	}
+
+	language = tag.String()
+
+	e.termsLock.RLock()
+	defer e.termsLock.RUnlock()
+
+	var kept, obsolete []poEntry
+	merged := make(map[string]bool) // Terms with an entry
+
+	for _, entry := range entries { // For each entry, in order
+		if entry.MsgId == "" || entry.Foreign || merged[entry.MsgId] || (entry.Obsolete && active[entry.MsgId]) { // If this is the header or not a Term, keep it as-is
+			if entry.Obsolete {
+				obsolete = append(obsolete, entry)
+			} else {
+				kept = append(kept, entry)
+			}
+
+			continue
+		}
+
+		term, exists := e.Config.Terms[entry.MsgId]
+
+		if !exists { // If the Term was removed, keep its translation as an obsolete entry
+			if !entry.Obsolete {
+				entry.Obsolete, entry.References, entry.Previous, entry.lines = true, nil, nil, nil
+				merge.Obsolete = append(merge.Obsolete, entry.MsgId)
+			}
+
+			obsolete = append(obsolete, entry)
+			continue
+		}
+
+		merged[entry.MsgId] = true
+		source := e.sourceComment(term)
+		previousSource := strings.Join(entry.Extracted, "\n")
+		fuzzy := entry.Obsolete && entry.translated() // A Term which was removed and added again may have changed
+		expected := poEntry{MsgId: entry.MsgId}
+		expected.setValue(language, term, e.Config.DefaultLanguage) // The entry as ConvertToPo would write it
+
+		if previousMsgId, hasPrevious := entry.previousMsgId(); hasPrevious && previousMsgId != entry.MsgId { // If a tool such as msgmerge matched the entry to a different msgid
+			fuzzy = fuzzy || entry.translated()
+		}
+
+		if (entry.MsgIdPlural == "") != (expected.MsgIdPlural == "") { // If the Term gained or lost plural forms, the translation of the other form can't be kept
+			fuzzy = fuzzy || entry.translated()
+			entry.MsgIdPlural, entry.MsgStr, entry.MsgStrPlural, entry.lines = expected.MsgIdPlural, expected.MsgStr, expected.MsgStrPlural, nil
+		}
+
+		if previousSource == "" && language == e.Config.DefaultLanguage && entry.translated() { // If the Po file has no source comment, such as one from ConvertToPo, its translations of the DefaultLanguage are the source
+			if _, translated := term[language]; translated && (entry.MsgStr != expected.MsgStr || !reflect.DeepEqual(entry.MsgStrPlural, expected.MsgStrPlural)) {
+				fuzzy = true
+			}
+		}
+
+		if previousSource != strings.Join(source, "\n") { // If the value of the DefaultLanguage changed, or wasn't provided
+			fromConfig := strings.HasPrefix(previousSource, e.Config.DefaultLanguage+": ") || strings.HasPrefix(previousSource, e.Config.DefaultLanguage+"[")
+			fuzzy = fuzzy || (fromConfig && entry.translated())
+			entry.Extracted, entry.lines = source, nil
+		}
+
+		if entry.Obsolete {
+			entry.Obsolete, entry.Previous, entry.lines = false, nil, nil
+		}
+
+		if fuzzy && !entry.hasFlag("fuzzy") { // If the translation needs to be reviewed
+			entry.Flags, entry.lines = append(entry.Flags, "fuzzy"), nil
+			merge.Fuzzy = append(merge.Fuzzy, entry.MsgId)
+		}
+
+		kept = append(kept, entry)
 	}
 
-	return poFile.String() // Return po format file string
+	termNames := make([]string, 0, len(e.Config.Terms))
+
+	for termName := range e.Config.Terms {
+		if !merged[termName] {
+			termNames = append(termNames, termName)
+		}
+	}
+
+	sort.Strings(termNames)
+
+	for _, termName := range termNames { // For each Term not in the Po file, in order
+		entry := poEntry{MsgId: termName, Extracted: e.sourceComment(e.Config.Terms[termName])}
+		entry.setValue(language, e.Config.Terms[termName], e.Config.DefaultLanguage)
+
+		kept = append(kept, entry)
+		merge.Added = append(merge.Added, termName)
+	}
+
+	var b strings.Builder
+
+	for _, entry := range append(kept, obsolete...) { // Obsolete entries are kept at the end, as gettext does
+		entry.write(&b)
+	}
+
+	merge.Content = strings.TrimSuffix(b.String(), "\n")
+	return merge, nil
+}
+
+// sourceComment gets the value of the DefaultLanguage of a Term as a comment for translators, which is empty if it isn't translated
+// Values with plural forms have a line for each form, such as en[one]: {count} file.
+func (e *Engine) sourceComment(term Term) []string {
+	value, translated := term[e.Config.DefaultLanguage]
+
+	if !translated {
+		return nil
+	} else if !value.IsPlural() {
+		return []string{e.Config.DefaultLanguage + ": " + value.Text}
+	}
+
+	var comment []string
+
+	for _, category := range []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther} { // For each plural form, in CLDR order
+		if form, exists := value.Plural[category]; exists {
+			comment = append(comment, e.Config.DefaultLanguage+"["+category+"]: "+form)
+		}
+	}
+
+	return comment
+}
+
+// value gets the translation of an entry in a language, with a plural form for each non-empty msgstr[n] of an entry with plural forms
+// The msgstr[n] of an entry are the plural categories of the language, in the order of PluralCategories.
+func (entry poEntry) value(language string) Value {
+	if entry.MsgIdPlural == "" {
+		return Value{Text: entry.MsgStr}
+	}
+
+	value := Value{Plural: make(map[string]string)}
+	categories := PluralCategories(language)
+
+	for index, form := range entry.MsgStrPlural {
+		if index < len(categories) && form != "" {
+			value.Plural[categories[index]] = form
+		}
+	}
+
+	if len(value.Plural) == 0 { // If none of the plural forms are translated
+		value.Plural = nil
+	}
+
+	return value
+}
+
+// setValue sets the translation of an entry to the value of a Term in a language, or an empty translation if it isn't translated
+// The entry has plural forms if the value does, or if the value of the DefaultLanguage does when the Term isn't translated.
+func (entry *poEntry) setValue(language string, term Term, defaultLanguage string) {
+	value, translated := term[language]
+	plural := value.IsPlural() || (!translated && term[defaultLanguage].IsPlural())
+	entry.MsgStr, entry.MsgIdPlural, entry.MsgStrPlural = "", "", nil
+
+	if !plural {
+		entry.MsgStr = value.Text
+		return
+	}
+
+	entry.MsgIdPlural = entry.MsgId
+
+	for _, category := range PluralCategories(language) { // For each plural category of the language, as msgstr[0], msgstr[1] and so on
+		entry.MsgStrPlural = append(entry.MsgStrPlural, value.Plural[category])
+	}
 }
@@ -0,0 +1,434 @@
+// This file contains the tests of reading, extracting and merging Po files
+
+package frala
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestUnquotePoString ensures the C escapes gettext writes are decoded, and malformed strings are rejected
+func TestUnquotePoString(t *testing.T) {
+	tests := []struct {
+		quoted string
+		want   string
+		valid  bool
+	}{
+		{`"hello"`, "hello", true},
+		{`""`, "", true},
+		{`"line\nnext\ttab\r"`, "line\nnext\ttab\r", true},
+		{`"it\'s \"quoted\" \\ \?"`, `it's "quoted" \ ?`, true},
+		{`"\a\b\f\v"`, "\a\b\f\v", true},
+		{`"\101\0"`, "A\x00", true},
+		{`"\303\244"`, "ä", true},
+		{`"\x41\xc3\xa4"`, "Aä", true},
+		{`"ä"`, "ä", true},
+		{`hello`, "", false},
+		{`"unterminated`, "", false},
+		{`"ends with \"`, "", false},
+		{`"un"quoted"`, "", false},
+		{`"\q"`, "", false},
+		{`"\x"`, "", false},
+		{`"\x100"`, "", false},
+		{`"\400"`, "", false},
+	}
+
+	for _, test := range tests {
+		got, unquoteErr := unquotePoString(test.quoted)
+
+		if test.valid != (unquoteErr == nil) {
+			t.Errorf("unquotePoString(%s) error = %v, want valid %v", test.quoted, unquoteErr, test.valid)
+		} else if got != test.want {
+			t.Errorf("unquotePoString(%s) = %q, want %q", test.quoted, got, test.want)
+		}
+	}
+}
+
+// TestParsePo ensures entries are read with their comments, flags and multi-line strings
+func TestParsePo(t *testing.T) {
+	entries, parseErr := parsePo(strings.Join([]string{
+		`msgid ""`,
+		`msgstr "Language: fi\n"`,
+		``,
+		`# Translator comment`,
+		`#. en: Hello`,
+		`#: index.html:3`,
+		`#, fuzzy, c-format`,
+		`#| msgid "hi"`,
+		`msgid "hello"`,
+		`msgstr ""`,
+		`"Hei "`,
+		`"maailma"`,
+		``,
+		`msgctxt "menu"`,
+		`msgid "file"`,
+		`msgstr "Tiedosto"`,
+		``,
+		`msgid "files"`,
+		`msgid_plural "files"`,
+		`msgstr[0] "{count} tiedosto"`,
+		`msgstr[1] ""`,
+		`"{count} "`,
+		`"tiedostoa"`,
+		``,
+		`#~ msgid "old"`,
+		`#~ msgstr "Vanha"`,
+	}, "\n"))
+
+	if parseErr != nil {
+		t.Fatal(parseErr)
+	}
+
+	if len(entries) != 5 {
+		t.Fatalf("parsePo read %d entries, want 5", len(entries))
+	}
+
+	if language := entries[0].headerField("Language"); language != "fi" {
+		t.Errorf("Language = %q, want fi", language)
+	}
+
+	hello := entries[1]
+
+	if hello.MsgId != "hello" || hello.MsgStr != "Hei maailma" {
+		t.Errorf("entry = %q: %q, want hello: Hei maailma", hello.MsgId, hello.MsgStr)
+	}
+
+	if !reflect.DeepEqual(hello.Flags, []string{"fuzzy", "c-format"}) || !reflect.DeepEqual(hello.References, []string{"index.html:3"}) {
+		t.Errorf("Flags = %v, References = %v", hello.Flags, hello.References)
+	}
+
+	if previous, hasPrevious := hello.previousMsgId(); !hasPrevious || previous != "hi" {
+		t.Errorf("previousMsgId = %q, %v, want hi", previous, hasPrevious)
+	}
+
+	if files := entries[3]; files.MsgIdPlural != "files" || !reflect.DeepEqual(files.MsgStrPlural, []string{"{count} tiedosto", "{count} tiedostoa"}) {
+		t.Errorf("plural entry = %q: %q", files.MsgIdPlural, files.MsgStrPlural)
+	}
+
+	if !entries[2].Foreign || !entries[4].Obsolete || entries[4].MsgStr != "Vanha" {
+		t.Errorf("msgctxt entry Foreign = %v, obsolete entry = %+v", entries[2].Foreign, entries[4])
+	}
+
+	entries, parseErr = parsePo("msgid \"a\"\nmsgstr \"A\"\n#, fuzzy\nmsgid \"b\"\nmsgstr \"\"\n\"B\"\nmsgctxt \"menu\"\nmsgid \"c\"\nmsgstr \"C\"\n#~ msgid \"d\"\n#~ msgstr \"D\"\n#~ \"E\"")
+
+	if parseErr != nil || len(entries) != 4 { // Entries without an empty line between them
+		t.Fatalf("parsePo = %d entries, %v, want 4", len(entries), parseErr)
+	}
+
+	if entries[0].MsgStr != "A" || !entries[1].hasFlag("fuzzy") || entries[1].MsgStr != "B" || !entries[2].Foreign || entries[3].MsgStr != "DE" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	for _, invalid := range []string{"msgid", `msgid "a"` + "\n" + `msgval "b"`, `msgid "a\z"`, `msgid "a"` + "\n" + `msgid_plural "a"` + "\n" + `msgstr[1] "b"`, `msgid "a"` + "\n" + `msgstr[x] "b"`} { // Each is rejected
+		if _, invalidErr := parsePo(invalid); invalidErr == nil {
+			t.Errorf("parsePo(%q) succeeded", invalid)
+		}
+	}
+}
+
+// TestConvertPo ensures Terms exported to a Po file are imported unchanged, without untranslated or fuzzy entries replacing their fallbacks
+func TestConvertPo(t *testing.T) {
+	config := `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {
+		"hello": {"en": "Hello", "fi": "Hei"},
+		"bye": {"en": "Goodbye"},
+		"quote": {"en": "Say \"hi\"", "fi": "Sano \"hei\"\nrivi"},
+		"files": {"en": {"one": "{count} file", "other": "{count} files"}, "fi": {"one": "{count} tiedosto", "other": "{count} tiedostoa"}},
+		"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}
+	}}`
+
+	files := Value{Plural: map[string]string{"one": "{count} tiedosto", "other": "{count} tiedostoa"}}
+
+	tests := []struct {
+		name    string
+		content string           // Content of the Po file to import, or empty to import the export of fi
+		want    map[string]Value // Value of each Term in fi, or an empty Value if it isn't translated
+	}{
+		{
+			name: "round trip",
+			want: map[string]Value{"hello": {Text: "Hei"}, "bye": {}, "quote": {Text: "Sano \"hei\"\nrivi"}, "files": files, "apples": {}},
+		},
+		{
+			name:    "untranslated and fuzzy",
+			content: "msgid \"\"\nmsgstr \"Language: fi.UTF-8\\n\"\n\nmsgid \"hello\"\nmsgstr \"Moi\"\n\nmsgid \"bye\"\nmsgstr \"\"\n\n#, fuzzy\nmsgid \"quote\"\nmsgstr \"Arvaus\"\n\n#~ msgid \"old\"\n#~ msgstr \"Vanha\"",
+			want:    map[string]Value{"hello": {Text: "Moi"}, "bye": {}, "quote": {Text: "Sano \"hei\"\nrivi"}, "files": files, "apples": {}},
+		},
+		{
+			name:    "plural forms",
+			content: "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\nmsgid \"files\"\nmsgstr \"Tiedostot\"\n\nmsgid \"apples\"\nmsgid_plural \"apples\"\nmsgstr[0] \"{count} omena\"\nmsgstr[1] \"\"\n\nmsgid \"bye\"\nmsgid_plural \"bye\"\nmsgstr[0] \"\"\nmsgstr[1] \"Näkemiin\"",
+			want:    map[string]Value{"files": files, "apples": {}, "bye": {Plural: map[string]string{"other": "Näkemiin"}}}, // Plural forms are kept, and only imported with their other form
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, dir := newTestEngine(t, config, nil)
+			content := test.content
+
+			if content == "" {
+				content = engine.ConvertToPo("fi")
+
+				if !strings.Contains(content, "msgid \"files\"\nmsgid_plural \"files\"\nmsgstr[0] \"{count} tiedosto\"\nmsgstr[1] \"{count} tiedostoa\"") {
+					t.Errorf("ConvertToPo = %q, want the plural forms of files", content)
+				}
+
+				if !strings.Contains(content, "msgid \"apples\"\nmsgid_plural \"apples\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"") { // Untranslated, with the plural forms of the DefaultLanguage
+					t.Errorf("ConvertToPo = %q, want empty plural forms of apples", content)
+				}
+			}
+
+			writeTestFiles(t, dir, map[string]string{"fi.po": content})
+
+			if convertErr := engine.ConvertFromPo(filepath.Join(dir, "fi.po")); convertErr != nil {
+				t.Fatal(convertErr)
+			}
+
+			for termName, want := range test.want {
+				if value := engine.Config.Terms[termName]["fi"]; !reflect.DeepEqual(value, want) {
+					t.Errorf("%s in fi = %+v, want %+v", termName, value, want)
+				}
+			}
+
+			if _, added := engine.Config.Terms["old"]; added {
+				t.Error("The obsolete entry was imported")
+			}
+
+			if got := engine.GetValue("hello", "de"); got != "Hello" { // Untranslated Terms still fall back
+				t.Errorf("GetValue(hello, de) = %q, want Hello", got)
+			}
+		})
+	}
+
+	engine, dir := newTestEngine(t, config, nil)
+	writeTestFiles(t, dir, map[string]string{"none.po": "msgid \"hello\"\nmsgstr \"Hei\""})
+
+	if convertErr := engine.ConvertFromPo(filepath.Join(dir, "none.po")); convertErr == nil { // Without a Language in the header
+		t.Error("ConvertFromPo without a language succeeded")
+	}
+}
+
+// TestExtractPot ensures every term used by the templates and their Fragments has one entry, referencing each line using it relative to the root
+func TestExtractPot(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello \"you\"", "fi": "Hei"}, "thanks": {"fi": "Kiitos"}, "unused": {"en": "Unused"}}}`, map[string]string{
+		"partials/header.html": "{{ type=\"term\" src=\"hello\" }}\n{{ type=\"term\" src=\"new\" }}",
+		"index.html":           "{{ type=\"term\" src=\"hello\" }} {{ type=\"term\" src=\"hello\" }}\n{{ type=\"fragment\" src=\"partials/header.html\" }}\n{{ type=\"term\" src=\"thanks\" }} {{ type=\"term\" src=\"frala.Direction\" }}",
+		"about.html":           "{{ type=\"fragment\" src=\"partials/header.html\" }}",
+		"plain.html":           "<p>No terms</p>",
+	})
+
+	header := "# Translation template of the terms used by the templates.\nmsgid \"\"\nmsgstr \"\"\n\"MIME-Version: 1.0\\n\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n\"Content-Transfer-Encoding: 8bit\\n\"\n\"X-Generator: Frala\\n\"\n"
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{
+			name:  "templates and fragments",
+			files: []string{"index.html", "about.html"},
+			want:  header + "\n#. en: Hello \"you\"\n#: index.html:1\n#: partials/header.html:1\nmsgid \"hello\"\nmsgstr \"\"\n\n#: partials/header.html:2\nmsgid \"new\"\nmsgstr \"\"\n\n#: index.html:3\nmsgid \"thanks\"\nmsgstr \"\"\n",
+		},
+		{
+			name:  "fragment",
+			files: []string{"about.html"},
+			want:  header + "\n#. en: Hello \"you\"\n#: partials/header.html:1\nmsgid \"hello\"\nmsgstr \"\"\n\n#: partials/header.html:2\nmsgid \"new\"\nmsgstr \"\"\n",
+		},
+		{
+			name:  "no terms",
+			files: []string{"plain.html"},
+			want:  header,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var files []string
+
+			for _, file := range test.files {
+				files = append(files, filepath.Join(dir, file))
+			}
+
+			pot, extractErr := engine.ExtractPot(files)
+
+			if extractErr != nil {
+				t.Fatal(extractErr)
+			}
+
+			if pot != test.want {
+				t.Errorf("ExtractPot = %q, want %q", pot, test.want)
+			}
+
+			if _, parseErr := parsePo(pot); parseErr != nil { // The template can be read back
+				t.Errorf("parsePo: %v", parseErr)
+			}
+		})
+	}
+
+	if _, added := engine.Config.Terms["new"]; added {
+		t.Error("ExtractPot added the undefined term to the Config")
+	}
+
+	if _, extractErr := engine.ExtractPot([]string{filepath.Join(dir, "none.html")}); extractErr == nil {
+		t.Error("ExtractPot of a missing template succeeded")
+	}
+}
+
+// TestMergePo ensures translations are kept, Terms are added and removed, and changed translations are flagged as fuzzy
+func TestMergePo(t *testing.T) {
+	config := `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {
+		"hello": {"en": "Hello", "fi": "Hei"},
+		"bye": {"en": "Goodbye", "fi": "Näkemiin"},
+		"new": {"en": "New", "fi": "Uusi"}
+	}}`
+
+	tests := []struct {
+		name     string
+		language string
+		content  string
+		added    []string
+		fuzzy    []string
+		obsolete []string
+	}{
+		{
+			name:    "unchanged",
+			content: "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\n#. en: Hello\nmsgid \"hello\"\nmsgstr \"Hei\"\n\n#. en: Goodbye\nmsgid \"bye\"\nmsgstr \"Näkemiin\"\n\n#. en: New\nmsgid \"new\"\nmsgstr \"Uusi\"",
+		},
+		{
+			name:     "added and removed",
+			content:  "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\n#. en: Hello\nmsgid \"hello\"\nmsgstr \"Hei\"\n\n#. en: Goodbye\nmsgid \"bye\"\nmsgstr \"Näkemiin\"\n\nmsgid \"removed\"\nmsgstr \"Poistettu\"",
+			added:    []string{"new"},
+			obsolete: []string{"removed"},
+		},
+		{
+			name:    "source changed",
+			content: "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\n#. en: Hi\nmsgid \"hello\"\nmsgstr \"Hei\"\n\n#. en: Goodbye\nmsgid \"bye\"\nmsgstr \"Näkemiin\"\n\n#. en: New\nmsgid \"new\"\nmsgstr \"Uusi\"",
+			fuzzy:   []string{"hello"},
+		},
+		{
+			name:    "previous msgid",
+			content: "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\n#| msgid \"hi\"\nmsgid \"hello\"\nmsgstr \"Hei\"\n\nmsgid \"bye\"\nmsgstr \"Näkemiin\"\n\nmsgid \"new\"\nmsgstr \"Uusi\"",
+			fuzzy:   []string{"hello"},
+		},
+		{
+			name:    "exported without source comments",
+			content: "msgid \"\"\nmsgstr \"Language: en\\n\"\n\nmsgid \"hello\"\nmsgstr \"Hi\"\n\nmsgid \"bye\"\nmsgstr \"Goodbye\"\n\nmsgid \"new\"\nmsgstr \"\"",
+			fuzzy:   []string{"hello"},
+		},
+		{
+			name:    "without empty lines",
+			content: "msgid \"\"\nmsgstr \"Language: fi\\n\"\n#. en: Hello\nmsgid \"hello\"\nmsgstr \"Hei\"\n#. en: Goodbye\nmsgid \"bye\"\nmsgstr \"Näkemiin\"\nmsgid \"new\"\nmsgstr \"Uusi\"",
+		},
+		{
+			name:     "language provided",
+			language: "fi_FI.UTF-8",
+			content:  "msgid \"hello\"\nmsgstr \"Hei\"",
+			added:    []string{"bye", "new"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, _ := newTestEngine(t, config, nil)
+			merge, mergeErr := engine.MergePo(test.content, test.language)
+
+			if mergeErr != nil {
+				t.Fatal(mergeErr)
+			}
+
+			if !reflect.DeepEqual(merge.Added, test.added) || !reflect.DeepEqual(merge.Fuzzy, test.fuzzy) || !reflect.DeepEqual(merge.Obsolete, test.obsolete) {
+				t.Errorf("Added = %v, Fuzzy = %v, Obsolete = %v, want %v, %v, %v", merge.Added, merge.Fuzzy, merge.Obsolete, test.added, test.fuzzy, test.obsolete)
+			}
+
+			if test.name == "unchanged" && merge.Content != test.content+"\n" { // Unchanged entries are written as they were read
+				t.Errorf("Content = %q, want it unchanged", merge.Content)
+			}
+
+			for _, termName := range test.obsolete {
+				if !strings.Contains(merge.Content, "#~ msgid \""+termName+"\"") {
+					t.Errorf("%s is not obsolete in %q", termName, merge.Content)
+				}
+			}
+
+			remerge, remergeErr := engine.MergePo(merge.Content, test.language)
+
+			if remergeErr != nil || remerge.Content != merge.Content { // Merging again changes nothing
+				t.Errorf("merging again = %q, %v, want %q", remerge.Content, remergeErr, merge.Content)
+			}
+		})
+	}
+
+	engine, _ := newTestEngine(t, config, nil)
+
+	if _, mergeErr := engine.MergePo("msgid \"hello\"\nmsgstr \"Hei\"", ""); mergeErr == nil { // Without a Language in the header or provided
+		t.Errorf("MergePo without a language succeeded")
+	}
+}
+
+// TestMergePoPlural ensures Terms with plural forms are merged with every form, and flagged as fuzzy when their forms change
+func TestMergePoPlural(t *testing.T) {
+	config := `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {
+		"files": {"en": {"one": "{count} file", "other": "{count} files"}, "fi": {"one": "{count} tiedosto", "other": "{count} tiedostoa"}},
+		"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}
+	}}`
+
+	header := "msgid \"\"\nmsgstr \"Language: fi\\n\"\n\n"
+	apples := "#. en[one]: {count} apple\n#. en[other]: {count} apples\nmsgid \"apples\"\nmsgid_plural \"apples\"\nmsgstr[0] \"\"\nmsgstr[1] \"\""
+
+	tests := []struct {
+		name     string
+		content  string
+		added    []string
+		fuzzy    []string
+		contains string
+	}{
+		{
+			name:     "added",
+			content:  header,
+			added:    []string{"apples", "files"},
+			contains: "#. en[one]: {count} file\n#. en[other]: {count} files\nmsgid \"files\"\nmsgid_plural \"files\"\nmsgstr[0] \"{count} tiedosto\"\nmsgstr[1] \"{count} tiedostoa\"",
+		},
+		{
+			name:     "kept",
+			content:  header + "#. en[one]: {count} file\n#. en[other]: {count} files\nmsgid \"files\"\nmsgid_plural \"files\"\nmsgstr[0] \"Yksi tiedosto\"\nmsgstr[1] \"{count} tiedostoa\"\n\n" + apples,
+			contains: "msgstr[0] \"Yksi tiedosto\"",
+		},
+		{
+			name:     "became plural",
+			content:  header + "#. en: {count} files\nmsgid \"files\"\nmsgstr \"Tiedostot\"\n\n" + apples,
+			fuzzy:    []string{"files"},
+			contains: "#, fuzzy\nmsgid \"files\"\nmsgid_plural \"files\"\nmsgstr[0] \"{count} tiedosto\"",
+		},
+		{
+			name:     "plural form changed",
+			content:  header + "#. en[one]: {count} file\n#. en[other]: {count} filez\nmsgid \"files\"\nmsgid_plural \"files\"\nmsgstr[0] \"Yksi tiedosto\"\nmsgstr[1] \"{count} tiedostoa\"\n\n" + apples,
+			fuzzy:    []string{"files"},
+			contains: "msgstr[0] \"Yksi tiedosto\"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, _ := newTestEngine(t, config, nil)
+			merge, mergeErr := engine.MergePo(test.content, "")
+
+			if mergeErr != nil {
+				t.Fatal(mergeErr)
+			}
+
+			if !reflect.DeepEqual(merge.Added, test.added) || !reflect.DeepEqual(merge.Fuzzy, test.fuzzy) {
+				t.Errorf("Added = %v, Fuzzy = %v, want %v, %v", merge.Added, merge.Fuzzy, test.added, test.fuzzy)
+			}
+
+			if !strings.Contains(merge.Content, test.contains) {
+				t.Errorf("Content = %q, want it to contain %q", merge.Content, test.contains)
+			}
+
+			remerge, remergeErr := engine.MergePo(merge.Content, "")
+
+			if remergeErr != nil || remerge.Content != merge.Content { // Merging again changes nothing
+				t.Errorf("merging again = %q, %v, want %q", remerge.Content, remergeErr, merge.Content)
+			}
+		})
+	}
+}
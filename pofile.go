@@ -0,0 +1,355 @@
+// This file contains reading and writing gettext Po files, keeping the comments and flags of each entry
+
+package frala
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// poEntry is a single entry of a gettext Po file, such as msgid "hello" with its comments
+type poEntry struct {
+	Comments     []string // Comments are translator comments (# comment), without the leading # and space
+	Extracted    []string // Extracted are comments for translators from the source (#. comment)
+	References   []string // References are the file:line each msgid is used at (#: file:line)
+	Flags        []string // Flags such as fuzzy (#, fuzzy)
+	Previous     []string // Previous are the previous msgid of a fuzzy entry (#| msgid "hello"), as-is
+	MsgId        string   // MsgId is the source string, which is the name of the Term
+	MsgIdPlural  string   // MsgIdPlural is the plural source string of an entry with plural forms, which is also the name of the Term
+	MsgStr       string   // MsgStr is the translation, which is empty in a Po template
+	MsgStrPlural []string // MsgStrPlural are the translations of each plural form (msgstr[n]) of an entry with plural forms
+	Obsolete     bool     // Obsolete is whether the entry is no longer used, written as #~ lines
+	Foreign      bool     // Foreign is whether the entry has a msgctxt, which Terms never have
+	lines        []string // lines the entry was read from, written as-is until the entry changes
+}
+
+// poHeader creates the header entry of a Po file from its fields, such as Content-Type, in order
+func poHeader(fields ...string) poEntry {
+	return poEntry{MsgStr: strings.Join(fields, "\n") + "\n"}
+}
+
+// write writes the entry to b, followed by an empty line
+func (entry poEntry) write(b *strings.Builder) {
+	if entry.lines != nil { // If the entry is unchanged since it was read, keep it exactly as it was
+		b.WriteString(strings.Join(entry.lines, "\n") + "\n\n")
+		return
+	}
+
+	for _, comment := range entry.Comments {
+		writeComment(b, "#", comment)
+	}
+
+	for _, comment := range entry.Extracted {
+		writeComment(b, "#.", comment)
+	}
+
+	for _, reference := range entry.References {
+		b.WriteString("#: " + reference + "\n")
+	}
+
+	if len(entry.Flags) != 0 {
+		b.WriteString("#, " + strings.Join(entry.Flags, ", ") + "\n")
+	}
+
+	for _, previous := range entry.Previous {
+		b.WriteString("#| " + previous + "\n")
+	}
+
+	prefix := ""
+
+	if entry.Obsolete { // Obsolete entries are kept as comments, so their translations aren't lost
+		prefix = "#~ "
+	}
+
+	writePoString(b, prefix, "msgid", entry.MsgId)
+
+	if entry.MsgIdPlural == "" {
+		writePoString(b, prefix, "msgstr", entry.MsgStr)
+		b.WriteString("\n")
+		return
+	}
+
+	writePoString(b, prefix, "msgid_plural", entry.MsgIdPlural)
+
+	for index, form := range entry.MsgStrPlural { // For each plural form, as msgstr[0], msgstr[1] and so on
+		writePoString(b, prefix, "msgstr["+strconv.Itoa(index)+"]", form)
+	}
+
+	b.WriteString("\n")
+}
+
+// writeComment writes a comment of a Po entry, with a line for each line of the comment
+func writeComment(b *strings.Builder, marker, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		if line == "" { // Empty lines are only the marker, without trailing whitespace
+			b.WriteString(marker + "\n")
+		} else {
+			b.WriteString(marker + " " + line + "\n")
+		}
+	}
+}
+
+// writePoString writes a keyword such as msgid with its quoted value, splitting values with several lines into a string per line as gettext does
+func writePoString(b *strings.Builder, prefix, keyword, value string) {
+	lines := strings.SplitAfter(value, "\n")
+
+	if lines[len(lines)-1] == "" { // Ignore the empty string after a final newline
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) <= 1 { // If the value fits on one line
+		b.WriteString(prefix + keyword + " " + quotePoString(value) + "\n")
+		return
+	}
+
+	b.WriteString(prefix + keyword + " \"\"\n")
+
+	for _, line := range lines {
+		b.WriteString(prefix + quotePoString(line) + "\n")
+	}
+}
+
+// quotePoString quotes a string of a Po file, escaping it as a C string
+func quotePoString(value string) string {
+	return `"` + poEscaper.Replace(value) + `"`
+}
+
+// poEscaper escapes the characters of a string which can't appear in a quoted Po string as-is
+var poEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// unquotePoString unquotes a string of a Po file, decoding its C escapes, such as \n, \' and octal or hexadecimal escapes
+func unquotePoString(quoted string) (string, error) {
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", errors.New("String is not quoted")
+	}
+
+	quoted = quoted[1 : len(quoted)-1]
+	var b strings.Builder
+
+	for index := 0; index < len(quoted); index++ { // For each byte, so octal and hexadecimal escapes can form UTF-8 sequences
+		char := quoted[index]
+
+		if char == '"' { // If a quote isn't escaped, it would end the string early
+			return "", errors.New("Unescaped quote in string")
+		} else if char != '\\' {
+			b.WriteByte(char)
+			continue
+		}
+
+		if index++; index == len(quoted) { // If the string ends with a backslash, escaping its closing quote
+			return "", errors.New("String ends with a backslash")
+		}
+
+		if escaped := strings.IndexByte(`ntrabfv`, quoted[index]); escaped != -1 { // If this is an escape of a control character
+			b.WriteByte("\n\t\r\a\b\f\v"[escaped])
+			continue
+		}
+
+		switch char = quoted[index]; {
+		case char == '\\' || char == '"' || char == '\'' || char == '?':
+			b.WriteByte(char)
+		case char >= '0' && char <= '7': // Up to three octal digits
+			value := 0
+
+			for digits := 0; digits < 3 && index < len(quoted) && quoted[index] >= '0' && quoted[index] <= '7'; digits++ {
+				value = value*8 + int(quoted[index]-'0')
+				index++
+			}
+
+			if value > 0xff {
+				return "", errors.New("Octal escape is out of range")
+			}
+
+			b.WriteByte(byte(value))
+			index-- // The loop moves past the last digit
+		case char == 'x': // Any number of hexadecimal digits
+			value, digits := 0, 0
+
+			for index+1 < len(quoted) && strings.IndexByte("0123456789abcdefABCDEF", quoted[index+1]) != -1 {
+				index++
+				digit, _ := strconv.ParseUint(quoted[index:index+1], 16, 8)
+				value, digits = value*16+int(digit), digits+1
+
+				if value > 0xff {
+					return "", errors.New("Hexadecimal escape is out of range")
+				}
+			}
+
+			if digits == 0 {
+				return "", errors.New("Hexadecimal escape has no digits")
+			}
+
+			b.WriteByte(byte(value))
+		default:
+			return "", errors.New("Unknown escape \\" + string(char))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// previousMsgId gets the previous msgid of an entry from its #| comments, and whether it has one
+func (entry poEntry) previousMsgId() (string, bool) {
+	var msgId string
+	var hasMsgId, inMsgId bool
+
+	for _, previous := range entry.Previous {
+		previous = strings.TrimSpace(previous)
+
+		if keyword, quoted, isKeyword := strings.Cut(previous, " "); isKeyword && !strings.HasPrefix(previous, `"`) { // If this is a keyword, such as msgid
+			inMsgId, previous = keyword == "msgid", strings.TrimSpace(quoted)
+			hasMsgId = hasMsgId || inMsgId
+		}
+
+		if value, unquoteErr := unquotePoString(previous); inMsgId && unquoteErr == nil {
+			msgId += value
+		}
+	}
+
+	return msgId, hasMsgId
+}
+
+// translated returns whether the entry has a translation, or a translation of any of its plural forms
+func (entry poEntry) translated() bool {
+	for _, form := range entry.MsgStrPlural {
+		if form != "" {
+			return true
+		}
+	}
+
+	return entry.MsgStr != ""
+}
+
+// hasFlag returns whether the entry has a flag, such as fuzzy
+func (entry poEntry) hasFlag(flag string) bool {
+	for _, entryFlag := range entry.Flags {
+		if entryFlag == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headerField gets the value of a field of a header entry, such as Language
+func (entry poEntry) headerField(name string) string {
+	for _, line := range strings.Split(entry.MsgStr, "\n") { // For each field, as Name: value
+		if fieldName, value, isField := strings.Cut(line, ":"); isField && strings.EqualFold(strings.TrimSpace(fieldName), name) {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// parsePo parses the entries of a Po file, keeping the lines of each so entries which don't change can be written as they were
+func parsePo(content string) ([]poEntry, error) {
+	var entries []poEntry
+	var entry poEntry
+	var keyword string // Keyword the quoted strings of the current line belong to, such as msgid
+
+	for index, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") { // For each line
+		trimmed := strings.TrimLeft(line, " \t") // Trailing whitespace is kept, as it may be part of a comment
+
+		if strings.TrimSpace(trimmed) == "" { // An empty line ends the entry
+			if entry.lines != nil {
+				entries = append(entries, entry)
+			}
+
+			entry, keyword = poEntry{}, ""
+			continue
+		}
+
+		if next := strings.TrimLeft(strings.TrimPrefix(trimmed, "#~"), " \t"); strings.HasPrefix(keyword, "msgstr") && !strings.HasPrefix(next, `"`) && !strings.HasPrefix(next, "msgstr") { // If a comment, msgctxt or msgid follows a msgstr, it starts the next entry even without an empty line
+			entries = append(entries, entry)
+			entry, keyword = poEntry{}, ""
+		}
+
+		entry.lines = append(entry.lines, line)
+
+		if strings.HasPrefix(trimmed, "#~") { // If this is part of an obsolete entry, parse it as a line of a regular entry
+			entry.Obsolete = true
+			trimmed = strings.TrimLeft(trimmed[2:], " \t")
+
+			if strings.HasPrefix(trimmed, "|") { // If this is the previous msgid of an obsolete entry, which isn't needed
+				continue
+			}
+		}
+
+		if strings.HasPrefix(trimmed, "#") { // If this is a comment
+			text := strings.TrimPrefix(trimmed[1:], " ")
+
+			if len(trimmed) > 1 && strings.ContainsRune(".:,|", rune(trimmed[1])) { // If this is a comment with a marker, such as #.
+				text = strings.TrimPrefix(trimmed[2:], " ")
+			}
+
+			switch {
+			case strings.HasPrefix(trimmed, "#."):
+				entry.Extracted = append(entry.Extracted, text)
+			case strings.HasPrefix(trimmed, "#:"):
+				entry.References = append(entry.References, strings.TrimSpace(text))
+			case strings.HasPrefix(trimmed, "#,"):
+				for _, flag := range strings.Split(text, ",") {
+					if flag = strings.TrimSpace(flag); flag != "" {
+						entry.Flags = append(entry.Flags, flag)
+					}
+				}
+			case strings.HasPrefix(trimmed, "#|"):
+				entry.Previous = append(entry.Previous, text)
+			default: // Translator comments
+				entry.Comments = append(entry.Comments, text)
+			}
+
+			continue
+		}
+
+		trimmed = strings.TrimSpace(trimmed)
+
+		if !strings.HasPrefix(trimmed, `"`) { // If this is a keyword, such as msgid "hello", rather than the continuation of the previous one
+			var quoted bool
+			keyword, trimmed, quoted = strings.Cut(trimmed, " ")
+
+			if !quoted {
+				return nil, errors.New("Invalid Po file on line " + strconv.Itoa(index+1) + ": " + keyword + " has no string")
+			}
+
+			trimmed = strings.TrimSpace(trimmed)
+		}
+
+		value, unquoteErr := unquotePoString(trimmed)
+
+		if unquoteErr != nil {
+			return nil, errors.New("Invalid Po file on line " + strconv.Itoa(index+1) + ": " + trimmed + " is not a valid string: " + unquoteErr.Error())
+		}
+
+		switch keyword {
+		case "msgid":
+			entry.MsgId += value
+		case "msgstr":
+			entry.MsgStr += value
+		case "msgid_plural":
+			entry.MsgIdPlural += value
+		case "msgctxt":
+			entry.Foreign = true
+		default:
+			form, formErr := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(keyword, "msgstr["), "]"))
+
+			if !strings.HasPrefix(keyword, "msgstr[") || !strings.HasSuffix(keyword, "]") || formErr != nil || form < 0 || form > len(entry.MsgStrPlural) { // If this isn't the current or next plural form
+				return nil, errors.New("Invalid Po file on line " + strconv.Itoa(index+1) + ": unknown keyword " + keyword)
+			}
+
+			if form == len(entry.MsgStrPlural) { // If this is the next plural form
+				entry.MsgStrPlural = append(entry.MsgStrPlural, "")
+			}
+
+			entry.MsgStrPlural[form] += value
+		}
+	}
+
+	if entry.lines != nil { // If the last entry isn't followed by an empty line
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,94 @@
+// This file contains the tests of rendering from readers to writers and reading templates from an FS
+
+package frala
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestRenderFS ensures templates and Fragments are read from an FS, and Fragments can't escape its root
+func TestRenderFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"site/index.html":        {Data: []byte(`<p>{{ type="term" src="hello" }}</p>{{ type="fragment" src="parts/footer.html" }}`)},
+		"site/parts/footer.html": {Data: []byte(`<footer>{{ type="fragment" src="/site/parts/links.html" }}</footer>`)},
+		"site/parts/links.html":  {Data: []byte(`links`)},
+		"site/escape.html":       {Data: []byte(`{{ type="fragment" src="../../secret.html" }}`)},
+		"site/parent.html":       {Data: []byte(`{{ type="fragment" src="../site/parts/links.html" }}`)},
+	}
+
+	engine, newErr := New(Options{Config: &ConfigOptions{DefaultLanguage: "en", Terms: map[string]Term{"hello": {"en": {Text: "Hello"}}}}, FS: fsys})
+
+	if newErr != nil {
+		t.Fatal(newErr)
+	}
+
+	tests := []struct {
+		file    string
+		content string
+		kind    ErrorKind // Kind of the Diagnostic, if any
+		failed  bool      // Whether the render fails, such as for a file which can't be read
+	}{
+		{file: "site/index.html", content: "<p>Hello</p><footer>links</footer>"},
+		{file: "/site/index.html", content: "<p>Hello</p><footer>links</footer>"},
+		{file: "site/parent.html", content: "links"},
+		{file: "site/escape.html", kind: ErrorOutsideRoot},
+		{file: "site/missing.html", failed: true},
+	}
+
+	for _, test := range tests {
+		var content strings.Builder
+		diagnostics, renderErr := engine.Render(&content, test.file, RenderOptions{})
+
+		if test.failed != (renderErr != nil) || content.String() != test.content {
+			t.Errorf("Render(%s) = %q, %v, want %q", test.file, content.String(), renderErr, test.content)
+		}
+
+		if test.kind != 0 && (len(diagnostics) != 1 || diagnostics[0].Kind != test.kind) {
+			t.Errorf("Render(%s) Diagnostics = %v, want one of %s", test.file, diagnostics, test.kind)
+		} else if test.kind == 0 && len(diagnostics) != 0 {
+			t.Errorf("Render(%s) Diagnostics = %v, want none", test.file, diagnostics)
+		}
+	}
+}
+
+// failingWriter is a Writer which fails every write
+type failingWriter struct{}
+
+// Write fails
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("Disk full")
+}
+
+// failingReader is a Reader which fails every read
+type failingReader struct{}
+
+// Read fails
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("Connection reset")
+}
+
+// TestRenderReader ensures content is rendered from a Reader, and read and write errors fail the render
+func TestRenderReader(t *testing.T) {
+	engine, dir := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"hello": {"en": "Hello"}}}`, map[string]string{
+		"parts/footer.html": "footer",
+	})
+
+	var content strings.Builder
+	diagnostics, renderErr := engine.RenderReader(&content, filepath.Join(dir, "page.html"), strings.NewReader(`{{ type="term" src="hello" }} {{ type="fragment" src="parts/footer.html" }}`), RenderOptions{})
+
+	if renderErr != nil || len(diagnostics) != 0 || content.String() != "Hello footer" {
+		t.Errorf("RenderReader = %q, %v, %v, want Hello footer", content.String(), diagnostics, renderErr)
+	}
+
+	if _, renderErr := engine.RenderReader(failingWriter{}, "page.html", strings.NewReader("text"), RenderOptions{}); renderErr == nil || renderErr.Error() != "Disk full" {
+		t.Errorf("RenderReader to a failing Writer = %v, want Disk full", renderErr)
+	}
+
+	if _, renderErr := engine.RenderReader(&content, "page.html", failingReader{}, RenderOptions{}); renderErr == nil || !strings.Contains(renderErr.Error(), "Connection reset") {
+		t.Errorf("RenderReader from a failing Reader = %v, want Connection reset", renderErr)
+	}
+}
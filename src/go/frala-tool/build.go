@@ -0,0 +1,292 @@
+// This file contains the build command of the Frala Tool, parsing a whole source tree in every language
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultLayout is the layout of the output tree when none is provided, such as out/fi/docs/index.html for docs/index.html in Finnish
+const DefaultLayout = "{lang}/{path}"
+
+// globList is a flag of globs, which may be repeated or comma-separated
+type globList []string
+
+// String returns the globs as comma-separated values
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+// Set adds each of the comma-separated globs
+func (g *globList) Set(value string) error {
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			*g = append(*g, glob)
+		}
+	}
+
+	return nil
+}
+
+// BuildOptions are the options for building a source tree
+type BuildOptions struct {
+	Source      string   // Source is the directory of templates and assets
+	Output      string   // Output is the directory the output tree is written to
+	Layout      string   // Layout is the path of each parsed template in the output tree, using {lang} and {path}
+	AssetLayout string   // AssetLayout is the path of each asset in the output tree. Assets are only copied once if it has no {lang}
+	Include     []string // Include are globs of the files to build. Defaults to every file
+	Exclude     []string // Exclude are globs of the files and directories to skip, such as partials/**
+}
+
+// Build parses every template of a source tree in every language, copying any assets, and exits non-zero if any template failed to parse
+func Build(args []string) int {
+	var opts BuildOptions
+
+	flags := NewFlagSet("build", "build [flags] <srcdir>", "Parses every template under srcdir in every language of the config, keeping the directory structure, and copies every other file as-is.")
+	flags.StringVar(&opts.Output, "out", "out", "Directory to write the output tree to.")
+	flags.StringVar(&opts.Layout, "layout", DefaultLayout, "Path of each parsed template in the output tree, where {lang} is the language and {path} is the path of the template in the source tree.")
+	flags.StringVar(&opts.AssetLayout, "asset-layout", DefaultLayout, "Path of each asset in the output tree. Assets are copied once if it has no {lang}.")
+	flags.Var((*globList)(&opts.Include), "include", "Globs of the files to build, such as **/*.html. Accepts comma-separated values and may be repeated.")
+	flags.Var((*globList)(&opts.Exclude), "exclude", "Globs of the files and directories to skip, such as partials/**. Accepts comma-separated values and may be repeated.")
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 1 { // If there isn't exactly one source directory
+		flags.Usage()
+		return ExitUsage
+	}
+
+	opts.Source = arguments[0]
+
+	if failed, buildErr := BuildTree(opts); buildErr != nil {
+		fmt.Fprintln(os.Stderr, buildErr)
+		return ExitFailure
+	} else if failed != 0 {
+		fmt.Printf("%d files failed to parse\n", failed)
+		return ExitFailure
+	}
+
+	return ExitSuccess
+}
+
+// BuildTree parses every template of a source tree in every language and copies its assets
+// Returns the number of templates which failed to parse, and any error walking the tree or copying assets.
+func BuildTree(opts BuildOptions) (int, error) {
+	if layoutErr := checkLayouts(opts, len(Engine.Config.Languages)); layoutErr != nil { // If templates or assets would overwrite each other
+		return 0, layoutErr
+	}
+
+	templates, assets, walkErr := sourceFiles(opts)
+
+	if walkErr != nil {
+		return 0, walkErr
+	}
+
+	files := make([]string, len(templates))
+
+	for index, template := range templates { // For each template, get its path on disk
+		files[index] = filepath.Join(opts.Source, filepath.FromSlash(template))
+	}
+
+	Engine.ClearFallbacks()
+	failed := 0
+	multilingualResponses := Engine.MultilingualParse(files)
+	var languages []string
+
+	for language := range multilingualResponses {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages) // Write each language in a consistent order
+
+	for _, language := range languages { // For each language
+		for index, parseResponse := range multilingualResponses[language] { // For each template, in the same order as files
+			if !WriteParseResponse(parseResponse, layoutFile(opts.Output, opts.Layout, language, templates[index])) {
+				failed++
+			}
+		}
+	}
+
+	PrintFallbacks()
+
+	copied := make(map[string]bool)
+
+	for _, asset := range assets { // For each asset, in each language if the layout has one
+		for _, language := range languages {
+			outputFile := layoutFile(opts.Output, opts.AssetLayout, language, asset)
+
+			if copied[outputFile] { // If the layout has no {lang}, the asset is only copied once
+				continue
+			}
+
+			copied[outputFile] = true
+
+			if copyErr := copyFile(filepath.Join(opts.Source, filepath.FromSlash(asset)), outputFile); copyErr != nil {
+				return failed, copyErr
+			}
+		}
+	}
+
+	return failed, nil
+}
+
+// sourceFiles walks a source tree, getting the slash-separated paths of its templates and assets relative to the source directory
+// Hidden files and directories, the output directory and the config are skipped, along with anything excluded or not included.
+func sourceFiles(opts BuildOptions) ([]string, []string, error) {
+	var templates, assets []string
+
+	absOutput, _ := filepath.Abs(opts.Output)
+	absConfig, _ := filepath.Abs(ConfigFile)
+
+	walkErr := filepath.WalkDir(opts.Source, func(file string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		rel, relErr := filepath.Rel(opts.Source, file)
+
+		if relErr != nil {
+			return relErr
+		}
+
+		if rel == "." { // The source directory itself is always walked
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		absFile, _ := filepath.Abs(file)
+		skip := strings.HasPrefix(entry.Name(), ".") || (opts.Output != "" && absFile == absOutput) || absFile == absConfig || matchesAny(opts.Exclude, rel)
+
+		if entry.IsDir() {
+			if skip {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if skip || (len(opts.Include) != 0 && !matchesAny(opts.Include, rel)) { // If this file shouldn't be built
+			return nil
+		}
+
+		if strings.HasSuffix(rel, ".html") { // Only HTML files are parsed, as with MultiParse
+			templates = append(templates, rel)
+		} else {
+			assets = append(assets, rel)
+		}
+
+		return nil
+	})
+
+	if walkErr != nil {
+		return nil, nil, fmt.Errorf("Failed to read %s: %w", opts.Source, walkErr)
+	}
+
+	return templates, assets, nil
+}
+
+// checkLayouts ensures each template is written to its own file, which needs a {path}, and a {lang} when building more than one language
+// Assets only need a {path}, since they are copied once when the AssetLayout has no {lang}.
+func checkLayouts(opts BuildOptions, languages int) error {
+	if !strings.Contains(opts.Layout, "{path}") {
+		return errors.New("Layout " + opts.Layout + " has no {path}, so every template would be written to the same file")
+	} else if languages > 1 && !strings.Contains(opts.Layout, "{lang}") {
+		return errors.New("Layout " + opts.Layout + " has no {lang}, so each language would overwrite the others")
+	} else if !strings.Contains(opts.AssetLayout, "{path}") {
+		return errors.New("Asset layout " + opts.AssetLayout + " has no {path}, so every asset would be written to the same file")
+	}
+
+	return nil
+}
+
+// layoutFile gets the file in the output directory for a slash-separated path in the source tree, by filling {lang} and {path} in the layout
+func layoutFile(output, layout, language, sourcePath string) string {
+	file := strings.NewReplacer("{lang}", language, "{path}", sourcePath).Replace(layout)
+	return filepath.Join(output, filepath.FromSlash(file))
+}
+
+// matchesAny returns whether the slash-separated path matches any of the globs
+func matchesAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matchGlob(glob, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob returns whether a slash-separated path matches a glob, where ** matches any number of directories
+// A glob without a / matches the name of a file or directory at any depth, as with .gitignore.
+func matchGlob(glob, name string) bool {
+	if !strings.Contains(glob, "/") { // If this is only a name
+		matched, _ := path.Match(glob, path.Base(name))
+		return matched
+	}
+
+	return matchSegments(strings.Split(strings.Trim(glob, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchSegments returns whether the segments of a path match the segments of a glob
+func matchSegments(glob, name []string) bool {
+	if len(glob) == 0 {
+		return len(name) == 0
+	}
+
+	if glob[0] == "**" { // If any number of directories match, including none
+		for index := 0; index <= len(name); index++ {
+			if matchSegments(glob[1:], name[index:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	matched, _ := path.Match(glob[0], name[0])
+	return matched && matchSegments(glob[1:], name[1:])
+}
+
+// copyFile copies an asset as-is, creating the directory it is copied to
+func copyFile(source, destination string) error {
+	fmt.Println("Copying " + source + " to: " + destination)
+
+	if mkdirErr := os.MkdirAll(filepath.Dir(destination), 0755); mkdirErr != nil {
+		return fmt.Errorf("Failed to create the directory of %s: %w", destination, mkdirErr)
+	}
+
+	sourceFile, openErr := os.Open(source)
+
+	if openErr != nil {
+		return fmt.Errorf("Failed to read %s: %w", source, openErr)
+	}
+
+	defer sourceFile.Close()
+
+	destinationFile, createErr := os.Create(destination)
+
+	if createErr != nil {
+		return fmt.Errorf("Failed to write %s: %w", destination, createErr)
+	}
+
+	if _, copyErr := io.Copy(destinationFile, sourceFile); copyErr != nil {
+		destinationFile.Close()
+		return fmt.Errorf("Failed to write %s: %w", destination, copyErr)
+	}
+
+	return destinationFile.Close()
+}
@@ -0,0 +1,144 @@
+// This file contains the tests of building a whole source tree in every language
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestMatchGlob ensures ** matches any number of directories, and globs without a / match a name at any depth
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		name  string
+		match bool
+	}{
+		{"*.html", "index.html", true},
+		{"*.html", "docs/guide/index.html", true},
+		{"*.html", "style.css", false},
+		{"partials", "partials", true},
+		{"partials/**", "partials/header.html", true},
+		{"partials/**", "docs/partials/header.html", false},
+		{"**/partials/**", "docs/partials/header.html", true},
+		{"**/*.html", "index.html", true},
+		{"**/*.html", "docs/guide/index.html", true},
+		{"docs/*.html", "docs/guide/index.html", false},
+		{"docs/**/index.html", "docs/index.html", true},
+		{"/docs/*.html", "docs/index.html", true},
+		{"[", "[", false}, // Malformed
+	}
+
+	for _, test := range tests {
+		if got := matchGlob(test.glob, test.name); got != test.match {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", test.glob, test.name, got, test.match)
+		}
+	}
+}
+
+// TestCheckLayouts ensures layouts which would write several templates or languages to the same file are rejected
+func TestCheckLayouts(t *testing.T) {
+	tests := []struct {
+		layout      string
+		assetLayout string
+		languages   int
+		valid       bool
+	}{
+		{DefaultLayout, DefaultLayout, 2, true},
+		{"{path}", "{path}", 1, true},
+		{"{path}.{lang}", "{path}", 2, true},
+		{"{path}", DefaultLayout, 2, false},
+		{"{lang}/index.html", DefaultLayout, 1, false},
+		{DefaultLayout, "assets", 2, false},
+	}
+
+	for _, test := range tests {
+		layoutErr := checkLayouts(BuildOptions{Layout: test.layout, AssetLayout: test.assetLayout}, test.languages)
+
+		if (layoutErr == nil) != test.valid {
+			t.Errorf("checkLayouts(%q, %q, %d) = %v, want valid %v", test.layout, test.assetLayout, test.languages, layoutErr, test.valid)
+		}
+	}
+}
+
+// TestBuildTree ensures every template is parsed in every language and every asset copied, following the layouts and skipping excluded and hidden files
+func TestBuildTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		layout      string
+		assetLayout string
+		exclude     []string
+		want        map[string]string // Content of each file of the output tree, or * for an asset
+	}{
+		{
+			name:        "default layouts",
+			layout:      DefaultLayout,
+			assetLayout: DefaultLayout,
+			want: map[string]string{
+				"en/index.html": "Hello", "en/docs/guide.html": "Guide", "en/partials/greeting.html": "Hello", "en/style.css": "*",
+				"fi/index.html": "Hei", "fi/docs/guide.html": "Opas", "fi/partials/greeting.html": "Hei", "fi/style.css": "*",
+			},
+		},
+		{
+			name:        "shared assets",
+			layout:      "{path}.{lang}",
+			assetLayout: "{path}",
+			exclude:     []string{"partials/**"},
+			want: map[string]string{
+				"index.html.en": "Hello", "docs/guide.html.en": "Guide",
+				"index.html.fi": "Hei", "docs/guide.html.fi": "Opas",
+				"style.css": "*",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := useTestConfig(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello", "fi": "Hei"}, "guide": {"en": "Guide", "fi": "Opas"}}}`, map[string]string{
+				"src/index.html":             `{{ type="fragment" src="partials/greeting.html" }}`,
+				"src/partials/greeting.html": `{{ type="term" src="hello" }}`,
+				"src/docs/guide.html":        `{{ type="term" src="guide" }}`,
+				"src/style.css":              "body {}",
+				"src/.hidden.html":           "Hidden",
+			})
+
+			output := filepath.Join(dir, "out")
+			failed, buildErr := BuildTree(BuildOptions{Source: filepath.Join(dir, "src"), Output: output, Layout: test.layout, AssetLayout: test.assetLayout, Exclude: test.exclude})
+
+			if failed != 0 || buildErr != nil {
+				t.Fatalf("BuildTree = %d, %v", failed, buildErr)
+			}
+
+			var got []string
+
+			filepath.Walk(output, func(file string, info os.FileInfo, walkErr error) error {
+				if walkErr == nil && !info.IsDir() {
+					rel, _ := filepath.Rel(output, file)
+					got = append(got, filepath.ToSlash(rel))
+				}
+
+				return walkErr
+			})
+
+			var want []string
+
+			for file, content := range test.want {
+				want = append(want, file)
+				written, _ := os.ReadFile(filepath.Join(output, filepath.FromSlash(file)))
+
+				if content != "*" && strings.TrimSpace(string(written)) != content {
+					t.Errorf("%s = %q, want %q", file, written, content)
+				}
+			}
+
+			sort.Strings(want)
+
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("Output tree = %v, want %v", got, want)
+			}
+		})
+	}
+}
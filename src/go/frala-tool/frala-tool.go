@@ -3,135 +3,258 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/JoshStrobl/frala"
-	"github.com/JoshStrobl/nflag"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// OriginalLanguage is the original value of frala
-var OriginalLanguage string
+// DefaultConfigFile is the config file used when a command has no --config
+const DefaultConfigFile = "frala.json"
 
-// TargetDirectory is the directory we should save parsed files or Po content to
-var TargetDirectory string
+// The exit codes of the Frala Tool
+const (
+	ExitSuccess = 0 // The command succeeded
+	ExitFailure = 1 // The command failed, such as a template failing to parse or an invalid config
+	ExitUsage   = 2 // The command was used incorrectly, such as with an unknown command, an unknown flag or a missing argument
+)
+
+// Engine is the Frala Engine used by the command being run, configured by ConfigFile
+var Engine *frala.Engine
+
+// ConfigFile is the config file of the Engine, set by the --config flag of each command
+var ConfigFile = DefaultConfigFile
+
+// EngineRoot is the directory Fragments of the Engine must resolve inside of, set by commands with a --root. Defaults to the directory of the ConfigFile
+var EngineRoot string
+
+// Command is a subcommand of the Frala Tool
+type Command struct {
+	Name    string                  // Name of the command, which may be in a group such as "po import"
+	Summary string                  // Summary of the command, shown in the help of the Frala Tool
+	Run     func(args []string) int // Run runs the command with the arguments after its name, returning the exit code
+}
+
+// Commands are the commands of the Frala Tool, in the order they are shown in its help
+var Commands []Command
 
 // Initialization
 func init() {
-	nflag.Configure(nflag.ConfigOptions{ShowHelpIfNoArgs: true, ProgramDescription: "Frala CLI tool for file parsing and Po conversion."})
-
-	nflag.Set("convert-terms", nflag.Flag{ // Create the convert-terms flag
-		Type:         "bool",
-		Descriptor:   "Use convert-terms to declare the action of Terms to Po conversion. Don't pass for Po to Terms conversion.",
-		AllowNothing: true,
-	})
-
-	nflag.Set("lang", nflag.Flag{ // Create the lang Flag
-		Descriptor:   "Language to parse files, Terms to Po conversion, or Po to Terms conversion.",
-		Type:         "string",
-		DefaultValue: frala.Config.DefaultLanguage, // Set DefaultValue to whatever is already set in frala
-		AllowNothing: true,                         // Allow nothing, since we'll just default to DefaultValue
-	})
-
-	nflag.Set("parse", nflag.Flag{ // Create the parse flag
-		Descriptor: "Files to parse. Accepts comma-separated values.",
-		Type:       "string",
-	})
-
-	nflag.Set("po", nflag.Flag{ // Create the po flag
-		Descriptor: "Po file you wish to convert to Terms or save Terms to.",
-		Type:       "string",
-	})
-
-	currentWorkingDirectory, getWdErr := os.Getwd()
-
-	if getWdErr != nil { // If there was an error getting the working directory
-		absolutePathToDir, absolutePathErr := filepath.Abs(".") // Attempt to get the absolute path of the directory
-
-		if absolutePathErr == nil { // If there was no issue getting the absolute path of the directory
-			currentWorkingDirectory = absolutePathToDir // Change currentWorkingDirectory to absolutePathToDir
-		} else { // If there was an issue getting the the current working directory
-			fmt.Println("Unable to determine the current working directory. Exiting.")
-			os.Exit(1)
+	Commands = []Command{
+		{Name: "init", Summary: "Create a frala.json config", Run: Init},
+		{Name: "render", Summary: "Parse templates in a language, optionally watching them for changes", Run: Render},
+		{Name: "build", Summary: "Parse every template of a source tree in every language", Run: Build},
+		{Name: "serve", Summary: "Serve templates in every language, reloading pages as they change", Run: Serve},
+		{Name: "lint", Summary: "Report problems in templates without writing them", Run: Lint},
+		{Name: "stats", Summary: "Report how completely each language is translated", Run: Stats},
+		{Name: "terms list", Summary: "List the values of the Terms of the config", Run: TermsList},
+		{Name: "terms get", Summary: "Print the values of a Term", Run: TermsGet},
+		{Name: "terms set", Summary: "Set the value of a Term in a language", Run: TermsSet},
+		{Name: "terms delete", Summary: "Delete Terms, or their values of a language", Run: TermsDelete},
+		{Name: "terms rename", Summary: "Rename a Term, keeping its values", Run: TermsRename},
+		{Name: "terms copy", Summary: "Copy a Term, along with its values, to a new Term", Run: TermsCopy},
+		{Name: "po import", Summary: "Import the translations of Po files into the config", Run: PoImport},
+		{Name: "po export", Summary: "Export the Terms of a language to a Po file", Run: PoExport},
+		{Name: "po extract", Summary: "Extract the terms used by templates into a Po template", Run: PoExtract},
+		{Name: "po merge", Summary: "Update Po files with the Terms of the config, keeping their translations", Run: PoMerge},
+	}
+}
+
+func main() {
+	os.Exit(Run(os.Args[1:]))
+}
+
+// Run runs the command named by the arguments, returning the exit code
+func Run(args []string) int {
+	if len(args) == 0 { // If no command was provided
+		PrintUsage(os.Stderr, "")
+		return ExitUsage
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" || args[0] == "-help" {
+		if len(args) > 1 { // If this is help for a command, such as help build
+			return Run(append(args[1:], "--help"))
+		}
+
+		PrintUsage(os.Stdout, "")
+		return ExitSuccess
+	}
+
+	for _, command := range Commands { // For each command, matching every word of its name
+		words := strings.Fields(command.Name)
+
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == command.Name {
+			return command.Run(args[len(words):])
 		}
 	}
 
-	nflag.Set("target-dir", nflag.Flag{ // Create the target-dir flag
-		Descriptor:   "Target directory to save parsed files or Po content to.",
-		DefaultValue: currentWorkingDirectory, // Current working directory
-		Type:         "string",
-	})
+	for _, command := range Commands { // If this is a group of commands, such as po, show the commands of the group
+		if strings.HasPrefix(command.Name, args[0]+" ") {
+			PrintUsage(os.Stderr, args[0])
+			return ExitUsage
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Unknown command: "+args[0])
+	PrintUsage(os.Stderr, "")
+	return ExitUsage
 }
 
-func main() {
-	nflag.Parse() // Parse nflag
+// PrintUsage prints the commands of the Frala Tool, or only those of a group such as po
+func PrintUsage(w io.Writer, group string) {
+	fmt.Fprintln(w, "Frala CLI tool for file parsing and Po conversion.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage: frala-tool <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
 
-	OriginalLanguage = frala.Config.DefaultLanguage                     // Set OriginalLanguage before doing any parsing or conversion
-	frala.Config.DefaultLanguage, _ = nflag.GetAsString("lang")         // Change DefaultLanguage to whatever may be set as lang. This may not change if no value is passed to lang
-	frala.Config.Direction = frala.GetDirection(frala.Config.Direction) // Ensure we have an accurate Direction
+	for _, command := range Commands {
+		if group == "" || strings.HasPrefix(command.Name, group+" ") {
+			fmt.Fprintf(w, "  %-12s %s\n", command.Name, command.Summary)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "frala-tool <command> --help" for the flags of a command.`)
+	fmt.Fprintf(w, "Exit codes: %d on success, %d on failure, %d on incorrect usage.\n", ExitSuccess, ExitFailure, ExitUsage)
+}
 
-	parseFiles, parseFilesErr := nflag.GetAsString("parse")
-	poFile, poFileErr := nflag.GetAsString("po")
+// NewFlagSet creates the flags of a command, including --config
+func NewFlagSet(name, usage, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.StringVar(&ConfigFile, "config", DefaultConfigFile, "Config file to read Terms and languages from, and save them to.")
+
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: frala-tool "+usage)
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), description)
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
 
-	TargetDirectory, _ = nflag.GetAsString("target-dir")
-	TargetDirectory += "/" // Ensure / is appending to end of path
+// ParseFlags parses the flags of a command, which may be before or after its arguments, such as build src --out=public
+// Returns the arguments, and false with the exit code if the command shouldn't run, such as for --help or an unknown flag.
+func ParseFlags(flags *flag.FlagSet, args []string) ([]string, int, bool) {
+	var arguments []string
 
-	if (parseFilesErr == nil) && (parseFiles != "") { // If we are parsing files
-		ParseFiles(parseFiles) // Call ParseFiles
-	} else if (poFileErr == nil) && (poFile != "") { // If we are doing Po conversion
-		convertTermsVal, convertTermsGetErr := nflag.GetAsBool("convert-terms") // Get the boolean value of convert-terms
+	for {
+		if parseErr := flags.Parse(args); errors.Is(parseErr, flag.ErrHelp) { // If help was requested, which has already been printed
+			return nil, ExitSuccess, false
+		} else if parseErr != nil { // If a flag is invalid, which has already been printed along with the usage
+			return nil, ExitUsage, false
+		}
 
-		if convertTermsGetErr != nil { // If there was an error getting convertTermsVal
-			convertTermsVal = false // Don't convert from Terms to Po
+		if flags.NArg() == 0 { // If every argument has been parsed
+			return arguments, ExitSuccess, true
 		}
 
-		PoConversion(convertTermsVal, poFile) // Do Po conversion
-	} else { // If we are neither parsing nor doing Po conversion
-		nflag.PrintFlags() // Print the nflag flags
+		arguments = append(arguments, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// OpenEngine creates the Engine from the ConfigFile, using an empty Config if it doesn't exist yet
+// The empty Config is saved to the ConfigFile if the command changes it, such as when importing a Po file.
+func OpenEngine() error {
+	var newErr error
+	Engine, newErr = frala.New(frala.Options{ConfigFile: ConfigFile, Root: EngineRoot})
+
+	if errors.Is(newErr, os.ErrNotExist) { // If there is no config file yet
+		Engine, newErr = frala.New(frala.Options{ConfigFile: ConfigFile, Config: &frala.ConfigOptions{}, Root: EngineRoot})
+	}
+
+	return newErr
+}
+
+// Setup parses the flags of a command and creates the Engine from its config
+// Returns the arguments, and false with the exit code if the command shouldn't run.
+func Setup(flags *flag.FlagSet, args []string) ([]string, int, bool) {
+	arguments, exitCode, shouldRun := ParseFlags(flags, args)
+
+	if !shouldRun {
+		return nil, exitCode, false
+	}
+
+	if engineErr := OpenEngine(); engineErr != nil { // If the config could not be used
+		fmt.Fprintln(os.Stderr, engineErr)
+		return nil, ExitFailure, false
+	}
+
+	return arguments, ExitSuccess, true
+}
+
+// ParseLanguage gets the canonical form of a --lang flag, defaulting to the DefaultLanguage of the Config
+// Accepts any BCP 47 tag or POSIX locale, such as pt-BR or pt_BR.UTF-8.
+func ParseLanguage(language string) (string, error) {
+	if language == "" { // If no language was provided
+		return Engine.Config.DefaultLanguage, nil
 	}
+
+	tag, tagErr := frala.ParseTag(language)
+
+	if tagErr != nil {
+		return "", tagErr
+	}
+
+	return tag.String(), nil
+}
+
+// OutputFile gets the file a parsed file is written to in the output directory, keeping its path relative to the working directory
+// Files outside of the working directory are written to the output directory by their base filename.
+func OutputFile(output, name string) string {
+	if rel, relErr := filepath.Rel(".", name); relErr == nil && !filepath.IsAbs(name) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Join(output, rel)
+	}
+
+	return filepath.Join(output, filepath.Base(name))
 }
 
-// ParseFiles will parse the files provided and export parsed to target directory
-func ParseFiles(parseFiles string) {
-	filesToParse := strings.Split(parseFiles, ",")   // Split the comma-separated parseFiles to filesToParse
-	parseResponses := frala.MultiParse(filesToParse) // Parse each file
-
-	for _, parseResponse := range parseResponses { // For each parseResponse
-		if parseResponse.Error == nil { // If there was no issue parsing this file
-			if parseResponse.Content != "" { // If the content is not empty
-				fileNameNoPath := filepath.Base(parseResponse.Name) // Get the base filename
-				fmt.Println("Writing content to: " + TargetDirectory + fileNameNoPath)
-				os.MkdirAll(TargetDirectory, 0755) // Ensure the directory exists
-				ioutil.WriteFile(TargetDirectory+fileNameNoPath, []byte(parseResponse.Content), 0755)
-			} else { // If there was no content in the parseResponse.Content
-				fmt.Println("No content provided via parsing: " + parseResponse.Name)
+// WriteParseResponse prints the Diagnostics of a parsed file and writes its content to the output file
+// Returns whether the file was parsed and written without an error.
+func WriteParseResponse(parseResponse frala.ParseResponse, outputFile string) bool {
+	for _, diagnostic := range parseResponse.Diagnostics { // For each Diagnostic of this file
+		fmt.Println(diagnostic.Error()) // Print the file:line:column diagnostic
+	}
+
+	if parseResponse.Error == nil { // If there was no issue parsing this file
+		if parseResponse.Content != "" { // If the content is not empty
+			fmt.Println("Writing content to: " + outputFile)
+
+			if mkdirErr := os.MkdirAll(filepath.Dir(outputFile), 0755); mkdirErr != nil { // If the directory could not be created
+				fmt.Println(mkdirErr)
+				return false
+			}
+
+			if writeErr := ioutil.WriteFile(outputFile, []byte(parseResponse.Content), 0644); writeErr != nil { // If the file could not be written
+				fmt.Println(writeErr)
+				return false
 			}
-		} else { // If there was an issue parsing this file
-			fmt.Println(parseResponse.Error) // Print the error
+		} else { // If there was no content in the parseResponse.Content
+			fmt.Println("No content provided via parsing: " + parseResponse.Name)
 		}
+	} else if len(parseResponse.Diagnostics) == 0 { // If there was an issue parsing this file not already printed as a Diagnostic
+		fmt.Println(parseResponse.Error) // Print the error
 	}
+
+	return parseResponse.Error == nil
 }
 
-// PoConversion will handle conversion to/from Po
-func PoConversion(convertTerms bool, poFile string) {
-	if strings.HasSuffix(poFile, ".po") { // If the file is a .po file
-		if convertTerms { // If we are converting Terms to Po
-			os.MkdirAll(TargetDirectory, 0755)                                    // Ensure the target directory exists
-			poFileContent := frala.ConvertToPo(frala.Config.DefaultLanguage)      // Convert the Terms of the language defined (or default language)
-			ioutil.WriteFile(TargetDirectory+poFile, []byte(poFileContent), 0755) // Save the Po contents to the poFile in the target directory
-		} else { // If we are converting Po to Terms
-			conversionError := frala.ConvertFromPo(poFile) // Convert the poFile to Frala Terms
-
-			if conversionError == nil { // If there was no conversion error
-				frala.Config.DefaultLanguage = OriginalLanguage // Ensure the default language is maintained after importing from Po
-				frala.SaveConfig()                              // Save the config
-			} else { // If there was a conversion error
-				fmt.Println(conversionError)
-			}
+// PrintFallbacks prints every Term which fell back to another language, so they can be translated
+func PrintFallbacks() {
+	if fallbacks := Engine.Fallbacks(); len(fallbacks) != 0 { // If any Terms fell back to another language, report them so they can be translated
+		fmt.Println("Fallbacks:")
+
+		for _, fallback := range fallbacks {
+			fmt.Println(fallback.String())
 		}
-	} else {
-		fmt.Println(poFile + " does not appear to be a Po file. Please ensure the extension is .po")
 	}
 }
@@ -0,0 +1,131 @@
+// This file contains the tests of running the commands of the Frala Tool
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useTestConfig writes a config and each file, keyed by its slash-separated path, to a temporary directory and opens the Engine from the config
+// Returns the directory. The ConfigFile and Engine are restored when the test finishes.
+func useTestConfig(t *testing.T, config string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	previousConfigFile, previousEngine := ConfigFile, Engine
+
+	t.Cleanup(func() {
+		ConfigFile, Engine = previousConfigFile, previousEngine
+	})
+
+	files["frala.json"] = config
+	writeTestFiles(t, dir, files)
+	ConfigFile = filepath.Join(dir, "frala.json")
+
+	if engineErr := OpenEngine(); engineErr != nil {
+		t.Fatalf("OpenEngine: %v", engineErr)
+	}
+
+	return dir
+}
+
+// writeTestFiles writes each file, keyed by its slash-separated path relative to dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+
+		if mkdirErr := os.MkdirAll(filepath.Dir(file), 0755); mkdirErr != nil {
+			t.Fatal(mkdirErr)
+		}
+
+		if writeErr := os.WriteFile(file, []byte(content), 0644); writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+}
+
+// TestRun ensures commands are matched by every word of their name, with the exit code of incorrect usage for unknown commands, groups and flags
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, ExitUsage},
+		{[]string{"help"}, ExitSuccess},
+		{[]string{"--help"}, ExitSuccess},
+		{[]string{"help", "build"}, ExitSuccess},
+		{[]string{"build", "-h"}, ExitSuccess},
+		{[]string{"po", "merge", "--help"}, ExitSuccess},
+		{[]string{"bogus"}, ExitUsage},
+		{[]string{"po"}, ExitUsage}, // A group, rather than a command
+		{[]string{"po", "bogus"}, ExitUsage},
+		{[]string{"terms"}, ExitUsage},
+		{[]string{"build", "--bogus"}, ExitUsage},
+		{[]string{"render", "--watch=maybe"}, ExitUsage},
+	}
+
+	for _, test := range tests {
+		if got := Run(test.args); got != test.want {
+			t.Errorf("Run(%q) = %d, want %d", test.args, got, test.want)
+		}
+	}
+}
+
+// TestParseFlags ensures flags may be before, between or after the arguments of a command
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args      []string
+		arguments []string
+		out       string
+	}{
+		{[]string{"src"}, []string{"src"}, "out"},
+		{[]string{"--out=public", "src"}, []string{"src"}, "public"},
+		{[]string{"src", "--out", "public"}, []string{"src"}, "public"},
+		{[]string{"a.html", "-out=public", "b.html"}, []string{"a.html", "b.html"}, "public"},
+		{[]string{"--", "--out=public"}, []string{"--out=public"}, "out"}, // Everything after -- is an argument
+		{nil, nil, "out"},
+	}
+
+	for _, test := range tests {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		out := flags.String("out", "out", "")
+		arguments, exitCode, shouldRun := ParseFlags(flags, test.args)
+
+		if !shouldRun || exitCode != ExitSuccess {
+			t.Errorf("ParseFlags(%q) = %d, %v, want it to run", test.args, exitCode, shouldRun)
+		}
+
+		if !reflect.DeepEqual(arguments, test.arguments) || *out != test.out {
+			t.Errorf("ParseFlags(%q) = %q with --out=%s, want %q with --out=%s", test.args, arguments, *out, test.arguments, test.out)
+		}
+	}
+}
+
+// TestParseLanguage ensures a --lang flag is canonical, defaulting to the DefaultLanguage of the Config
+func TestParseLanguage(t *testing.T) {
+	useTestConfig(t, `{"DefaultLanguage": "en_GB"}`, map[string]string{})
+
+	tests := []struct {
+		language string
+		want     string
+		invalid  bool
+	}{
+		{"", "en-GB", false},
+		{"pt_BR.UTF-8", "pt-BR", false},
+		{"FI", "fi", false},
+		{"!!", "", true},
+	}
+
+	for _, test := range tests {
+		got, languageErr := ParseLanguage(test.language)
+
+		if got != test.want || (languageErr != nil) != test.invalid {
+			t.Errorf("ParseLanguage(%q) = %q, %v, want %q", test.language, got, languageErr, test.want)
+		}
+	}
+}
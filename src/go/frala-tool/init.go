@@ -0,0 +1,66 @@
+// This file contains the init command of the Frala Tool, creating a config
+
+package main
+
+import (
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"strings"
+)
+
+// Init creates a config with a DefaultLanguage, Languages and no Terms
+func Init(args []string) int {
+	flags := NewFlagSet("init", "init [flags]", "Creates a config with a DefaultLanguage, Languages and no Terms.")
+	language := flags.String("lang", "en", "DefaultLanguage of the config.")
+	languages := flags.String("languages", "", "Languages of the config. Accepts comma-separated values. Defaults to the DefaultLanguage.")
+	force := flags.Bool("force", false, "Overwrite the config if it already exists.")
+
+	arguments, exitCode, shouldRun := ParseFlags(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 0 {
+		fmt.Fprintln(os.Stderr, "Unexpected arguments: "+strings.Join(arguments, " "))
+		return ExitUsage
+	}
+
+	if _, statErr := os.Stat(ConfigFile); statErr == nil && !*force { // If we would overwrite a config
+		fmt.Fprintln(os.Stderr, ConfigFile+" already exists. Use --force to overwrite it.")
+		return ExitFailure
+	}
+
+	config := &frala.ConfigOptions{DefaultLanguage: *language, Terms: make(map[string]frala.Term)}
+
+	for _, configLanguage := range strings.Split(*languages, ",") { // For each language
+		if configLanguage = strings.TrimSpace(configLanguage); configLanguage != "" {
+			config.Languages = append(config.Languages, configLanguage)
+		}
+	}
+
+	if len(config.Languages) == 0 { // If no Languages were provided, only use the DefaultLanguage
+		config.Languages = []string{*language}
+	}
+
+	for _, configLanguage := range append([]string{config.DefaultLanguage}, config.Languages...) { // Ensure every language is valid before creating the config
+		if _, tagErr := frala.ParseTag(configLanguage); tagErr != nil {
+			fmt.Fprintln(os.Stderr, tagErr)
+			return ExitUsage
+		}
+	}
+
+	var newErr error
+	Engine, newErr = frala.New(frala.Options{ConfigFile: ConfigFile, Config: config}) // Canonicalizes each language
+
+	if newErr == nil {
+		newErr = Engine.SaveConfig()
+	}
+
+	if newErr != nil {
+		fmt.Fprintln(os.Stderr, newErr)
+		return ExitFailure
+	}
+
+	fmt.Println("Created " + ConfigFile)
+	return ExitSuccess
+}
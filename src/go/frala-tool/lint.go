@@ -0,0 +1,103 @@
+// This file contains the lint command of the Frala Tool, reporting problems in templates without writing them
+
+package main
+
+import (
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Lint lints every template provided, or under each directory provided, and exits non-zero if it has any problem
+func Lint(args []string) int {
+	flags := NewFlagSet("lint", "lint [flags] [file.html|dir]...", "Parses each template, and every template under each directory, without writing them, printing each problem as file:line:column: message [kind].\nReports terms which are not defined, Terms which no template uses, Terms without a value for a language of the config, unknown types and attributes, Fragments which can't be read, and include cycles.")
+	skip := flags.String("skip", "", "Kinds of problems to ignore, such as unused-term when only linting some templates. Accepts comma-separated values.")
+	paths, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	}
+
+	skipped := make(map[frala.ErrorKind]bool)
+
+	for _, kindName := range strings.Split(*skip, ",") { // For each kind of problem to ignore
+		if kindName = strings.TrimSpace(kindName); kindName == "" {
+			continue
+		}
+
+		kind, kindErr := frala.ParseErrorKind(kindName)
+
+		if kindErr != nil {
+			fmt.Fprintln(os.Stderr, kindErr)
+			return ExitUsage
+		}
+
+		skipped[kind] = true
+	}
+
+	if len(paths) == 0 { // If no templates were provided, lint the working directory
+		paths = []string{"."}
+	}
+
+	files, filesErr := templateFiles(paths)
+
+	if filesErr != nil {
+		fmt.Fprintln(os.Stderr, filesErr)
+		return ExitFailure
+	}
+
+	diagnostics, lintErr := Engine.Lint(files)
+
+	if lintErr != nil { // If a template could not be read
+		fmt.Fprintln(os.Stderr, lintErr)
+		return ExitFailure
+	}
+
+	problems := 0
+
+	for _, diagnostic := range diagnostics { // For each problem which isn't ignored
+		if !skipped[diagnostic.Kind] {
+			fmt.Println(diagnostic.Error() + " [" + diagnostic.Kind.String() + "]")
+			problems++
+		}
+	}
+
+	if problems != 0 {
+		fmt.Printf("%d problems found\n", problems)
+		return ExitFailure
+	}
+
+	return ExitSuccess
+}
+
+// templateFiles gets the templates provided, and the templates under each directory provided
+func templateFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths { // For each file or directory
+		fileInfo, statErr := os.Stat(path)
+
+		if statErr != nil {
+			return nil, statErr
+		}
+
+		if !fileInfo.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		templates, _, walkErr := sourceFiles(BuildOptions{Source: path})
+
+		if walkErr != nil {
+			return nil, walkErr
+		}
+
+		for _, template := range templates {
+			files = append(files, filepath.Join(path, filepath.FromSlash(template)))
+		}
+	}
+
+	return files, nil
+}
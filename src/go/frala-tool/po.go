@@ -0,0 +1,215 @@
+// This file contains the po commands of the Frala Tool, converting between Terms and gettext Po files
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PoImport imports the translations of each Po file into the Terms of the config, saving it
+func PoImport(args []string) int {
+	flags := NewFlagSet("po import", "po import [flags] <file.po>...", "Imports the translations of each Po file into the Terms of the config, in the language declared by the Po file, and saves the config.")
+	poFiles, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(poFiles) == 0 { // If no Po files were provided
+		flags.Usage()
+		return ExitUsage
+	}
+
+	for _, poFile := range poFiles { // Ensure every file is a Po file before importing any of them
+		if !strings.HasSuffix(poFile, ".po") {
+			fmt.Fprintln(os.Stderr, poFile+" does not appear to be a Po file. Please ensure the extension is .po")
+			return ExitUsage
+		}
+	}
+
+	for _, poFile := range poFiles { // For each Po file
+		if conversionErr := Engine.ConvertFromPo(poFile); conversionErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to import %s: %s\n", poFile, conversionErr)
+			return ExitFailure
+		}
+
+		fmt.Println("Imported " + poFile)
+	}
+
+	if saveErr := Engine.SaveConfig(); saveErr != nil {
+		fmt.Fprintln(os.Stderr, saveErr)
+		return ExitFailure
+	}
+
+	return ExitSuccess
+}
+
+// PoExport exports the Terms of a language to a Po file, or to stdout
+func PoExport(args []string) int {
+	flags := NewFlagSet("po export", "po export [flags]", "Exports the Terms of a language to a Po file. Terms which are not translated into the language have an empty msgstr.")
+	language := flags.String("lang", "", "Language of the Terms to export. Defaults to the DefaultLanguage of the config.")
+	output := flags.String("out", "", "Po file to write to, such as po/fi.po. Defaults to stdout.")
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 0 { // If a Po file was provided without --out
+		fmt.Fprintln(os.Stderr, "Unexpected arguments: "+strings.Join(arguments, " ")+". Use --out to choose the Po file.")
+		return ExitUsage
+	}
+
+	exportLanguage, languageErr := ParseLanguage(*language)
+
+	if languageErr != nil { // If the language is invalid
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	poContent := Engine.ConvertToPo(exportLanguage)
+
+	if *output == "" { // If we are writing to stdout
+		fmt.Print(poContent)
+		return ExitSuccess
+	}
+
+	if !strings.HasSuffix(*output, ".po") {
+		fmt.Fprintln(os.Stderr, *output+" does not appear to be a Po file. Please ensure the extension is .po")
+		return ExitUsage
+	}
+
+	os.MkdirAll(filepath.Dir(*output), 0755) // Ensure the directory exists
+
+	if writeErr := ioutil.WriteFile(*output, []byte(poContent), 0644); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %s\n", *output, writeErr)
+		return ExitFailure
+	}
+
+	fmt.Println("Exported " + exportLanguage + " to " + *output)
+	return ExitSuccess
+}
+
+// PoExtract extracts the terms used by templates into a Po template, or to stdout
+func PoExtract(args []string) int {
+	flags := NewFlagSet("po extract", "po extract [flags] [file.html|dir]...", "Extracts the terms used by each template, and every template under each directory (the working directory by default), into a Po template with a reference to every use of each term.")
+	output := flags.String("out", "", "Po template to write to, such as po/messages.pot. Defaults to stdout.")
+
+	paths, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if *output != "" && !strings.HasSuffix(*output, ".pot") {
+		fmt.Fprintln(os.Stderr, *output+" does not appear to be a Po template. Please ensure the extension is .pot")
+		return ExitUsage
+	}
+
+	if len(paths) == 0 { // If no templates were provided, use the working directory
+		paths = []string{"."}
+	}
+
+	files, filesErr := templateFiles(paths)
+
+	if filesErr != nil {
+		fmt.Fprintln(os.Stderr, filesErr)
+		return ExitFailure
+	}
+
+	potContent, extractErr := Engine.ExtractPot(files)
+
+	if extractErr != nil { // If a template could not be read
+		fmt.Fprintln(os.Stderr, extractErr)
+		return ExitFailure
+	}
+
+	if *output == "" { // If we are writing to stdout
+		fmt.Print(potContent)
+		return ExitSuccess
+	}
+
+	os.MkdirAll(filepath.Dir(*output), 0755) // Ensure the directory exists
+
+	if writeErr := ioutil.WriteFile(*output, []byte(potContent), 0644); writeErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %s\n", *output, writeErr)
+		return ExitFailure
+	}
+
+	terms := strings.Count(potContent, "\nmsgid ") - 1 // Every entry other than the header
+	fmt.Println("Extracted " + strconv.Itoa(terms) + " terms to " + *output)
+	return ExitSuccess
+}
+
+// PoMerge merges the Terms of the config into each Po file, keeping their translations
+func PoMerge(args []string) int {
+	flags := NewFlagSet("po merge", "po merge [flags] <file.po>...", "Updates each Po file with the Terms of the config, as msgmerge does: translations, comments and headers are kept, new Terms are added,\nentries of removed Terms become obsolete #~ entries, and translations are flagged as fuzzy when the value of the DefaultLanguage changes.")
+	language := flags.String("lang", "", "Language of the Po files. Defaults to the Language in the header of each Po file.")
+	dryRun := flags.Bool("dry-run", false, "Print what would change without writing the Po files.")
+
+	poFiles, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(poFiles) == 0 { // If no Po files were provided
+		flags.Usage()
+		return ExitUsage
+	}
+
+	for _, poFile := range poFiles { // Ensure every file is a Po file before merging any of them
+		if !strings.HasSuffix(poFile, ".po") {
+			fmt.Fprintln(os.Stderr, poFile+" does not appear to be a Po file. Please ensure the extension is .po")
+			return ExitUsage
+		}
+	}
+
+	mergeLanguage, languageErr := optionalLanguage(*language)
+
+	if languageErr != nil {
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	for _, poFile := range poFiles { // For each Po file
+		content, readErr := ioutil.ReadFile(poFile)
+
+		if readErr != nil {
+			fmt.Fprintln(os.Stderr, readErr)
+			return ExitFailure
+		}
+
+		merge, mergeErr := Engine.MergePo(string(content), mergeLanguage)
+
+		if mergeErr != nil {
+			fmt.Fprintf(os.Stderr, "Failed to merge %s: %s\n", poFile, mergeErr)
+			return ExitFailure
+		}
+
+		if !*dryRun {
+			mode := os.FileMode(0644)
+
+			if fileInfo, statErr := os.Stat(poFile); statErr == nil { // Keep the permissions of the Po file
+				mode = fileInfo.Mode().Perm()
+			}
+
+			if writeErr := ioutil.WriteFile(poFile, []byte(merge.Content), mode); writeErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write %s: %s\n", poFile, writeErr)
+				return ExitFailure
+			}
+		}
+
+		fmt.Printf("Merged %s: %d added, %d fuzzy, %d obsolete\n", poFile, len(merge.Added), len(merge.Fuzzy), len(merge.Obsolete))
+		printChangedTerms("Added", merge.Added)
+		printChangedTerms("Fuzzy", merge.Fuzzy)
+		printChangedTerms("Obsolete", merge.Obsolete)
+	}
+
+	return ExitSuccess
+}
+
+// printChangedTerms prints the Terms of a kind of change to a Po file, if there are any
+func printChangedTerms(change string, termNames []string) {
+	if len(termNames) != 0 {
+		fmt.Println("  " + change + ": " + strings.Join(termNames, ", "))
+	}
+}
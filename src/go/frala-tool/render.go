@@ -0,0 +1,61 @@
+// This file contains the render command of the Frala Tool, parsing templates in a language
+
+package main
+
+import (
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"strings"
+)
+
+// Render parses the templates provided in a language, writing them to the output directory, and exits non-zero if any template failed to parse
+func Render(args []string) int {
+	flags := NewFlagSet("render", "render [flags] <file.html>...", "Parses each template in a language, writing it to the output directory with its path relative to the working directory.")
+	language := flags.String("lang", "", "Language to parse in. Defaults to the DefaultLanguage of the config.")
+	output := flags.String("out", ".", "Directory to write parsed files to.")
+	watch := flags.Bool("watch", false, "Parse the files again whenever their templates, Fragments or Terms change, until stopped.")
+
+	files, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(files) == 0 { // If no templates were provided
+		flags.Usage()
+		return ExitUsage
+	}
+
+	renderLanguage, languageErr := ParseLanguage(*language)
+
+	if languageErr != nil { // If the language is invalid
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	if *watch { // If we should keep parsing as files change
+		Watch(files, []string{renderLanguage}, *output)
+		return ExitSuccess
+	}
+
+	return RenderFiles(files, renderLanguage, *output)
+}
+
+// RenderFiles parses the HTML files provided in a language and writes them to the output directory
+// Returns ExitFailure if any file failed to parse.
+func RenderFiles(files []string, language, output string) int {
+	exitCode := ExitSuccess
+
+	for _, file := range files { // For each file
+		if !strings.HasSuffix(file, ".html") { // Only HTML files are parsed, as with MultiParse
+			fmt.Fprintln(os.Stderr, "Skipping "+file+", since it is not an HTML file")
+			continue
+		}
+
+		if !WriteParseResponse(Engine.ParseWith(file, frala.RenderOptions{Language: language}), OutputFile(output, file)) {
+			exitCode = ExitFailure
+		}
+	}
+
+	PrintFallbacks()
+	return exitCode
+}
@@ -0,0 +1,298 @@
+// This file contains the serve command of the Frala Tool, a local development server which reloads pages as they change
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"html"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LiveReloadPath is the path of the server-sent events pages listen to for reloads
+const LiveReloadPath = "/_frala/events"
+
+// liveReloadScript is injected into every page, reloading it when the server sends a reload event
+const liveReloadScript = `<script>new EventSource("` + LiveReloadPath + `").addEventListener("reload", function () { location.reload(); });</script>`
+
+// Serve starts a local HTTP server rendering templates on request, reloading pages whenever a template, Fragment or Term changes
+func Serve(args []string) int {
+	flags := NewFlagSet("serve", "serve [flags]", "Renders templates on request in any language of the config, chosen with a path prefix such as /fi/ or with ?lang=fi, reloading pages as they change.")
+	address := flags.String("addr", "localhost:8080", "Address to listen on.")
+	flags.StringVar(&EngineRoot, "root", ".", "Directory to serve templates and assets from, which Fragments must resolve inside of.")
+	root := &EngineRoot
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 0 {
+		fmt.Fprintln(os.Stderr, "Unexpected arguments: "+strings.Join(arguments, " "))
+		return ExitUsage
+	}
+
+	handler := frala.NewHandler(Engine)
+	handler.Root = *root
+	handler.LanguagePrefix = true
+	handler.Debug = true // Show the Diagnostics of broken templates in the browser as an error page, so they are noticed
+
+	server := &devServer{templates: handler, assets: http.FileServer(http.Dir(*root)), reloads: &reloadBroker{clients: make(map[chan bool]bool)}}
+	go server.watch(*root)
+
+	fmt.Println("Serving " + *root + " on http://" + *address + "/")
+
+	listenErr := http.ListenAndServe(*address, server)
+	fmt.Fprintln(os.Stderr, listenErr)
+	return ExitFailure
+}
+
+// devServer serves templates and assets, injecting the live reload script into every page
+type devServer struct {
+	templates *frala.Handler // Handler rendering templates
+	assets    http.Handler   // Handler serving everything other than templates
+	reloads   *reloadBroker  // Broker sending reload events to pages
+}
+
+// ServeHTTP serves the reload events, a template or an asset, depending on the request path
+func (s *devServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == LiveReloadPath {
+		s.reloads.ServeHTTP(w, r)
+		return
+	}
+
+	_, rest, hasPrefix := Engine.SplitLanguagePrefix(r.URL.Path)
+
+	if extension := path.Ext(r.URL.Path); extension != "" && extension != ".html" { // If this is an asset, which may be relative to a language prefix such as /fi/style.css
+		if hasPrefix {
+			r = r.Clone(r.Context())
+			r.URL.Path = rest
+		}
+
+		s.assets.ServeHTTP(w, r)
+		return
+	}
+
+	response := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+	s.templates.ServeHTTP(response, r)
+	response.writeTo(w)
+}
+
+// bufferedResponse is an http.ResponseWriter keeping the response of a template, so the live reload script can be injected into it
+type bufferedResponse struct {
+	header http.Header  // Headers of the response
+	status int          // Status code of the response
+	body   bytes.Buffer // Body of the response
+}
+
+// Header gets the headers of the response
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+// Write buffers content of the response
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+// WriteHeader sets the status code of the response
+func (b *bufferedResponse) WriteHeader(status int) {
+	b.status = status
+}
+
+// writeTo writes the response with the live reload script injected, turning plain text errors into pages so they reload once fixed
+func (b *bufferedResponse) writeTo(w http.ResponseWriter) {
+	body := b.body.Bytes()
+	contentType := b.header.Get("Content-Type")
+
+	if b.status >= http.StatusBadRequest && strings.HasPrefix(contentType, "text/plain") { // If this is an error, such as a template with Diagnostics
+		body = []byte("<!DOCTYPE html>\n<html><body><pre>" + html.EscapeString(string(body)) + "</pre></body></html>\n")
+		b.header.Set("Content-Type", "text/html; charset=utf-8")
+		b.header.Del("X-Content-Type-Options")
+		b.header.Set("Content-Length", strconv.Itoa(len(body)))
+	} else if b.status != http.StatusOK || !strings.HasPrefix(contentType, "text/html") { // If this isn't a page, such as a redirect
+		for name, values := range b.header {
+			w.Header()[name] = values
+		}
+
+		w.WriteHeader(b.status)
+		w.Write(body)
+		return
+	}
+
+	if len(body) != 0 { // If there is a body to inject into, rather than a HEAD request
+		body = injectScript(body)
+	}
+
+	if contentLength, parseErr := strconv.Atoi(b.header.Get("Content-Length")); parseErr == nil { // Ensure the length includes the script, including for HEAD requests
+		b.header.Set("Content-Length", strconv.Itoa(contentLength+len(liveReloadScript)))
+	}
+
+	for name, values := range b.header {
+		w.Header()[name] = values
+	}
+
+	w.WriteHeader(b.status)
+	w.Write(body)
+}
+
+// injectScript inserts the live reload script before the closing body tag of a page, or at its end if it has none
+func injectScript(page []byte) []byte {
+	index := len(page) // If the page has no closing body tag, the script is added at its end
+
+	for start := len(page) - len("</body>"); start >= 0; start-- { // Find the last closing body tag, in any case. Only ASCII is folded, so the index is of the page itself
+		if bytes.EqualFold(page[start:start+len("</body>")], []byte("</body>")) {
+			index = start
+			break
+		}
+	}
+
+	injected := make([]byte, 0, len(page)+len(liveReloadScript))
+	injected = append(injected, page[:index]...)
+	injected = append(injected, liveReloadScript...)
+	return append(injected, page[index:]...)
+}
+
+// reloadBroker sends reload events to every connected page
+type reloadBroker struct {
+	lock    sync.Mutex         // Lock guarding the clients
+	clients map[chan bool]bool // Channels of each connected page
+}
+
+// ServeHTTP streams reload events to a page until it disconnects
+func (b *reloadBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, canFlush := w.(http.Flusher)
+
+	if !canFlush { // If the response can't be streamed
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	reload := make(chan bool, 1)
+
+	b.lock.Lock()
+	b.clients[reload] = true
+	b.lock.Unlock()
+
+	defer func() {
+		b.lock.Lock()
+		delete(b.clients, reload)
+		b.lock.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done(): // If the page disconnected
+			return
+		case <-reload:
+			fmt.Fprint(w, "event: reload\ndata: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// Broadcast sends a reload event to every connected page
+func (b *reloadBroker) Broadcast() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for reload := range b.clients {
+		select {
+		case reload <- true:
+		default: // A reload is already pending for this page
+		}
+	}
+}
+
+// watch reloads every page whenever a file under the root or the config file changes, reloading the config if it changed
+func (s *devServer) watch(root string) {
+	configFile, _ := filepath.Abs(ConfigFile)
+	states := treeStates(root)
+	configState := statFile(configFile)
+	delete(states, configFile) // The config is checked separately, since it must be reloaded
+
+	for {
+		time.Sleep(WatchInterval)
+
+		var changedPaths []string
+		currentStates := treeStates(root)
+		delete(currentStates, configFile)
+
+		for file, current := range currentStates { // For each file, which may be new
+			if previous, existed := states[file]; !existed || current != previous {
+				changedPaths = append(changedPaths, file)
+			}
+		}
+
+		for file := range states { // For each file which was removed
+			if _, exists := currentStates[file]; !exists {
+				changedPaths = append(changedPaths, file)
+			}
+		}
+
+		states = currentStates
+
+		if current := statFile(configFile); current != configState { // If the config changed, reload its Terms
+			configState = current
+			changedPaths = append(changedPaths, configFile)
+
+			if reloadErr := Engine.ReloadConfig(); reloadErr != nil { // If the config is now invalid, keep using the previous Config until it is fixed
+				fmt.Println(reloadErr)
+			}
+		}
+
+		if len(changedPaths) == 0 {
+			continue
+		}
+
+		sort.Strings(changedPaths)
+
+		for _, file := range changedPaths {
+			fmt.Println("Changed: " + file)
+		}
+
+		s.reloads.Broadcast()
+	}
+}
+
+// treeStates gets the state of every file under a directory, skipping hidden files and directories such as .git
+func treeStates(root string) map[string]fileState {
+	states := make(map[string]fileState)
+
+	filepath.WalkDir(root, func(file string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil { // If this file can't be read, such as if it was just removed
+			return nil
+		}
+
+		if file != root && strings.HasPrefix(entry.Name(), ".") { // If this is hidden
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.IsDir() {
+			absFile, _ := filepath.Abs(file)
+			states[absFile] = statFile(absFile)
+		}
+
+		return nil
+	})
+
+	return states
+}
@@ -0,0 +1,79 @@
+// This file contains the tests of the local development server
+
+package main
+
+import (
+	"github.com/JoshStrobl/frala"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestInjectScript ensures the live reload script is inserted before the last closing body tag, or at the end of a page without one
+func TestInjectScript(t *testing.T) {
+	tests := map[string]string{
+		"<html><body><p>Hi</p></body></html>":   "<html><body><p>Hi</p>" + liveReloadScript + "</body></html>",
+		"<BODY>Hi</BODY>":                       "<BODY>Hi" + liveReloadScript + "</BODY>",
+		"<body><pre></body></pre>Text</body>\n": "<body><pre></body></pre>Text" + liveReloadScript + "</body>\n",
+		"<p>Fragment</p>":                       "<p>Fragment</p>" + liveReloadScript,
+		"":                                      liveReloadScript,
+		"ȺȺȺȺȺȺȺȺ</body>":                       "ȺȺȺȺȺȺȺȺ" + liveReloadScript + "</body>", // Lowercasing changes the length of the text before the tag
+		"<body>İ</BoDy>\n":                      "<body>İ" + liveReloadScript + "</BoDy>\n",
+	}
+
+	for page, want := range tests {
+		if got := string(injectScript([]byte(page))); got != want {
+			t.Errorf("injectScript(%q) = %q, want %q", page, got, want)
+		}
+	}
+}
+
+// TestDevServer ensures pages in each language have the live reload script, while assets, redirects and reload events are served as-is
+func TestDevServer(t *testing.T) {
+	dir := useTestConfig(t, `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello", "fi": "Hei"}}}`, map[string]string{
+		"index.html":  `<html><body>{{ type="term" src="hello" }}</body></html>`,
+		"broken.html": `<html><body>{{ type="fragment" src="missing.html" }}</body></html>`,
+		"style.css":   "body {}",
+	})
+
+	handler := frala.NewHandler(Engine)
+	handler.Root = dir
+	handler.LanguagePrefix = true
+	handler.Debug = true
+	server := &devServer{templates: handler, assets: http.FileServer(http.Dir(dir)), reloads: &reloadBroker{clients: make(map[chan bool]bool)}}
+
+	tests := []struct {
+		target   string
+		status   int
+		contains string
+		injected bool
+	}{
+		{"/index.html", http.StatusOK, "Hello", true},
+		{"/fi/index.html", http.StatusOK, "Hei", true},
+		{"/index.html?lang=fi", http.StatusOK, "Hei", true},
+		{"/fi/style.css", http.StatusOK, "body {}", false}, // Assets are relative to the language prefix
+		{"/style.css", http.StatusOK, "body {}", false},
+		{"/fi", http.StatusMovedPermanently, "", false},
+		{"/broken.html", http.StatusInternalServerError, "missing.html", true}, // Errors are pages, so they reload once fixed
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.target, nil))
+		body := recorder.Body.String()
+
+		if recorder.Code != test.status || !strings.Contains(body, test.contains) {
+			t.Errorf("GET %s = %d %q, want %d containing %q", test.target, recorder.Code, body, test.status, test.contains)
+		}
+
+		if injected := strings.Contains(body, liveReloadScript); injected != test.injected {
+			t.Errorf("GET %s injected the script = %v, want %v", test.target, injected, test.injected)
+		}
+
+		if contentLength := recorder.Header().Get("Content-Length"); contentLength != "" && contentLength != strconv.Itoa(len(body)) {
+			t.Errorf("GET %s Content-Length = %s, want %d", test.target, contentLength, len(body))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// This file contains the stats command of the Frala Tool, reporting how completely each language is translated
+
+package main
+
+import (
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Stats prints the translation coverage of each language of the config as a table, JSON or Markdown badges
+func Stats(args []string) int {
+	flags := NewFlagSet("stats", "stats [flags] [--weighted [file.html|dir]...]", "Prints how many Terms of the config each language is translated into, falls back to another language for, or is missing.")
+	format := flags.String("format", "table", "Format to print the coverage in: table, json or badge (a Markdown badge per language).")
+	weighted := flags.Bool("weighted", false, "Weight each Term by how often the templates provided, or under each directory provided (the working directory by default), use it.")
+
+	paths, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(paths) != 0 && !*weighted { // If templates were provided, but won't be used
+		fmt.Fprintln(os.Stderr, "Templates are only used with --weighted")
+		return ExitUsage
+	} else if *format != "table" && *format != "json" && *format != "badge" {
+		fmt.Fprintln(os.Stderr, *format+" is not a format. Formats are: table, json, badge")
+		return ExitUsage
+	}
+
+	var files []string
+
+	if *weighted { // If Terms should be weighted by their uses
+		if len(paths) == 0 { // If no templates were provided, use the working directory
+			paths = []string{"."}
+		}
+
+		var filesErr error
+
+		if files, filesErr = templateFiles(paths); filesErr != nil {
+			fmt.Fprintln(os.Stderr, filesErr)
+			return ExitFailure
+		}
+	}
+
+	coverages, coverageErr := Engine.Coverage(files)
+
+	if coverageErr != nil { // If a template could not be read
+		fmt.Fprintln(os.Stderr, coverageErr)
+		return ExitFailure
+	}
+
+	switch *format {
+	case "json":
+		return printJSON(coverages)
+	case "badge":
+		for _, coverage := range coverages {
+			fmt.Println(coverageBadge(coverage))
+		}
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Language\tTranslated\tFallback\tMissing\tTotal")
+
+		for _, coverage := range coverages {
+			fmt.Fprintf(w, "%s\t%d (%.1f%%)\t%d (%.1f%%)\t%d (%.1f%%)\t%d\n", coverage.Language, coverage.Translated, coverage.TranslatedPercent, coverage.Fallback, coverage.FallbackPercent, coverage.Missing, coverage.MissingPercent, coverage.Total)
+		}
+
+		w.Flush()
+	}
+
+	return ExitSuccess
+}
+
+// coverageBadge gets a Markdown badge of the percentage of a language which is translated, using shields.io
+func coverageBadge(coverage frala.Coverage) string {
+	color := "red"
+
+	if coverage.TranslatedPercent >= 90 {
+		color = "brightgreen"
+	} else if coverage.TranslatedPercent >= 75 {
+		color = "yellow"
+	} else if coverage.TranslatedPercent >= 50 {
+		color = "orange"
+	}
+
+	label := strings.NewReplacer("-", "--", "_", "__").Replace(coverage.Language) // Dashes and underscores are escaped by doubling them
+	percent := fmt.Sprintf("%.0f", coverage.TranslatedPercent)
+
+	return fmt.Sprintf("![%s %s%% translated](https://img.shields.io/badge/%s-%s%%25-%s)", coverage.Language, percent, label, percent, color)
+}
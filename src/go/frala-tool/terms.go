@@ -0,0 +1,340 @@
+// This file contains the terms commands of the Frala Tool, managing the Terms of the config
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TermsList lists the values of the Terms of the config, optionally only those of a language or with a prefix
+func TermsList(args []string) int {
+	flags := NewFlagSet("terms list", "terms list [flags]", "Lists the values of the Terms of the config as Term, language and value separated by tabs. Terms without any values are listed on their own.")
+	language := flags.String("lang", "", "Only list the values of this language.")
+	prefix := flags.String("prefix", "", "Only list Terms whose names start with this prefix, such as nav.")
+	asJSON := flags.Bool("json", false, "Print the Terms as a JSON object of Terms, as in the config.")
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 0 {
+		fmt.Fprintln(os.Stderr, "Unexpected arguments: "+strings.Join(arguments, " "))
+		return ExitUsage
+	}
+
+	filterLanguage, languageErr := optionalLanguage(*language)
+
+	if languageErr != nil {
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	terms := make(map[string]frala.Term)
+
+	for termName, term := range Engine.Config.Terms { // For each Term with the prefix, with only the values of the language
+		if strings.HasPrefix(termName, *prefix) {
+			terms[termName] = filterTerm(term, filterLanguage)
+		}
+	}
+
+	if *asJSON {
+		return printJSON(terms)
+	}
+
+	for _, termName := range sortedTermNames(terms) { // For each Term, in order
+		if len(terms[termName]) == 0 && filterLanguage == "" { // If the Term has no values, such as a Term used before being translated
+			fmt.Println(termName)
+		}
+
+		printValues(termName, terms[termName])
+	}
+
+	return ExitSuccess
+}
+
+// TermsGet prints the values of a Term, or only its value of a language
+func TermsGet(args []string) int {
+	flags := NewFlagSet("terms get", "terms get [flags] <term>", "Prints the values of a Term as language and value separated by tabs, or only its value of a language.")
+	language := flags.String("lang", "", "Only print the value of this language, without falling back to other languages.")
+	asJSON := flags.Bool("json", false, "Print the Term as a JSON object of languages, as in the config.")
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	filterLanguage, languageErr := optionalLanguage(*language)
+
+	if languageErr != nil {
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	termName := arguments[0]
+	term, exists := Engine.Config.Terms[termName]
+
+	if !exists {
+		fmt.Fprintln(os.Stderr, "Term "+termName+" does not exist")
+		return ExitFailure
+	}
+
+	term = filterTerm(term, filterLanguage)
+
+	if filterLanguage != "" && len(term) == 0 { // If the Term isn't translated into the language
+		fmt.Fprintln(os.Stderr, "Term "+termName+" is not translated into "+filterLanguage)
+		return ExitFailure
+	}
+
+	if *asJSON {
+		return printJSON(term)
+	} else if filterLanguage != "" { // If only the value is needed, print it on its own so it can be used in scripts
+		fmt.Println(term[filterLanguage].String())
+		return ExitSuccess
+	}
+
+	for _, language := range sortedLanguages(term) {
+		fmt.Println(language + "\t" + term[language].String())
+	}
+
+	return ExitSuccess
+}
+
+// TermsSet sets the value of a Term in a language, or its plural forms, and saves the config
+func TermsSet(args []string) int {
+	flags := NewFlagSet("terms set", "terms set [flags] <term> <value>\n       frala-tool terms set --plural [flags] <term> <category>=<form>...", "Sets the value of a Term in a language, creating the Term if it doesn't exist, and saves the config. Values are ICU MessageFormat.")
+	language := flags.String("lang", "", "Language of the value. Defaults to the DefaultLanguage of the config.")
+	plural := flags.Bool("plural", false, "Set plural forms, provided as category=form, such as one=\"# file\" other=\"# files\".")
+
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) < 2 || (!*plural && len(arguments) != 2) {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	setLanguage, languageErr := ParseLanguage(*language)
+
+	if languageErr != nil {
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	termName := arguments[0]
+
+	if !*plural { // If this is a single value
+		if _, messageErr := frala.ParseMessage(arguments[1]); messageErr != nil { // Ensure the value is valid, so the config can still be read
+			fmt.Fprintln(os.Stderr, "Invalid value: "+messageErr.Error())
+			return ExitFailure
+		}
+
+		Engine.SetValue(termName, setLanguage, arguments[1])
+		return saveTerms("Set " + termName + " in " + setLanguage)
+	}
+
+	forms := make(map[string]string)
+
+	for _, argument := range arguments[1:] { // For each plural form
+		category, form, hasForm := strings.Cut(argument, "=")
+
+		if !hasForm {
+			fmt.Fprintln(os.Stderr, argument+" is not a plural form. Plural forms are provided as category=form, such as other=\"# files\"")
+			return ExitUsage
+		}
+
+		forms[category] = form
+	}
+
+	for category, form := range forms { // Ensure each form is valid, so the config can still be read
+		if _, messageErr := frala.ParseMessage(form); messageErr != nil {
+			fmt.Fprintln(os.Stderr, "Invalid "+category+" form: "+messageErr.Error())
+			return ExitFailure
+		}
+	}
+
+	if setErr := Engine.SetPluralValue(termName, setLanguage, forms); setErr != nil { // If a category isn't a CLDR plural category, or there is no other form
+		fmt.Fprintln(os.Stderr, setErr)
+		return ExitFailure
+	}
+
+	return saveTerms("Set the plural forms of " + termName + " in " + setLanguage)
+}
+
+// TermsDelete deletes a Term, or only its value of a language, and saves the config
+func TermsDelete(args []string) int {
+	flags := NewFlagSet("terms delete", "terms delete [flags] <term>...", "Deletes each Term, or only its value of a language, and saves the config.")
+	language := flags.String("lang", "", "Only delete the value of this language.")
+
+	termNames, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(termNames) == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	deleteLanguage, languageErr := optionalLanguage(*language)
+
+	if languageErr != nil {
+		fmt.Fprintln(os.Stderr, languageErr)
+		return ExitUsage
+	}
+
+	for _, termName := range termNames { // Ensure every Term exists before deleting any of them
+		term, exists := Engine.Config.Terms[termName]
+
+		if !exists {
+			fmt.Fprintln(os.Stderr, "Term "+termName+" does not exist")
+			return ExitFailure
+		} else if _, translated := term[deleteLanguage]; deleteLanguage != "" && !translated {
+			fmt.Fprintln(os.Stderr, "Term "+termName+" is not translated into "+deleteLanguage)
+			return ExitFailure
+		}
+	}
+
+	for _, termName := range termNames {
+		if deleteLanguage != "" { // If only the value of a language should be deleted
+			Engine.DeleteValue(termName, deleteLanguage)
+		} else {
+			Engine.DeleteTerm(termName)
+		}
+	}
+
+	if deleteLanguage != "" {
+		return saveTerms("Deleted " + strings.Join(termNames, ", ") + " in " + deleteLanguage)
+	}
+
+	return saveTerms("Deleted " + strings.Join(termNames, ", "))
+}
+
+// TermsRename renames a Term, keeping its values, and saves the config
+func TermsRename(args []string) int {
+	return moveTerm("rename", "Renames a Term, keeping its values, and saves the config.", args, false)
+}
+
+// TermsCopy copies a Term, along with its values, to a new Term and saves the config
+func TermsCopy(args []string) int {
+	return moveTerm("copy", "Copies a Term, along with its values, to a new Term and saves the config.", args, true)
+}
+
+// moveTerm runs a command renaming a Term, or copying it if keep is set
+func moveTerm(name, description string, args []string, keep bool) int {
+	flags := NewFlagSet("terms "+name, "terms "+name+" [flags] <term> <new term>", description)
+	arguments, exitCode, shouldRun := Setup(flags, args)
+
+	if !shouldRun {
+		return exitCode
+	} else if len(arguments) != 2 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	move, change := Engine.RenameTerm, "Renamed "
+
+	if keep { // If the original Term should be kept
+		move, change = Engine.CopyTerm, "Copied "
+	}
+
+	if moveErr := move(arguments[0], arguments[1]); moveErr != nil {
+		fmt.Fprintln(os.Stderr, moveErr)
+		return ExitFailure
+	}
+
+	return saveTerms(change + arguments[0] + " to " + arguments[1])
+}
+
+// saveTerms saves the config after changing its Terms, printing what changed
+func saveTerms(change string) int {
+	if saveErr := Engine.SaveConfig(); saveErr != nil {
+		fmt.Fprintln(os.Stderr, saveErr)
+		return ExitFailure
+	}
+
+	fmt.Println(change)
+	return ExitSuccess
+}
+
+// optionalLanguage gets the canonical form of a --lang flag filtering values, which is empty if no language was provided
+func optionalLanguage(language string) (string, error) {
+	if language == "" {
+		return "", nil
+	}
+
+	return ParseLanguage(language)
+}
+
+// filterTerm gets the values of a Term, or only its value of a language if one is provided
+func filterTerm(term frala.Term, language string) frala.Term {
+	if language == "" {
+		return term
+	}
+
+	filtered := make(frala.Term)
+
+	if value, exists := term[language]; exists {
+		filtered[language] = value
+	}
+
+	return filtered
+}
+
+// printValues prints each value of a Term as Term, language and value separated by tabs, with plural forms as JSON
+func printValues(termName string, term frala.Term) {
+	for _, language := range sortedLanguages(term) {
+		value, _ := json.Marshal(term[language])
+
+		if !term[language].IsPlural() { // Print single values as-is, rather than as a quoted JSON string
+			value = []byte(term[language].Text)
+		}
+
+		fmt.Println(termName + "\t" + language + "\t" + string(value))
+	}
+}
+
+// printJSON prints a value as indented JSON
+func printJSON(value interface{}) int {
+	content, encodeErr := json.MarshalIndent(value, "", "\t")
+
+	if encodeErr != nil {
+		fmt.Fprintln(os.Stderr, encodeErr)
+		return ExitFailure
+	}
+
+	fmt.Println(string(content))
+	return ExitSuccess
+}
+
+// sortedTermNames gets the names of the Terms, in order
+func sortedTermNames(terms map[string]frala.Term) []string {
+	termNames := make([]string, 0, len(terms))
+
+	for termName := range terms {
+		termNames = append(termNames, termName)
+	}
+
+	sort.Strings(termNames)
+	return termNames
+}
+
+// sortedLanguages gets the languages of a Term, in order
+func sortedLanguages(term frala.Term) []string {
+	languages := make([]string, 0, len(term))
+
+	for language := range term {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages
+}
@@ -0,0 +1,167 @@
+// This file contains the tests of the terms commands, managing the Terms of the config
+
+package main
+
+import (
+	"encoding/json"
+	"github.com/JoshStrobl/frala"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testTerms are the Terms of the config the terms commands are tested with
+const testTerms = `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "empty": {}, "hello": {"en": "Hello", "fi": "Hei"}, "nav.home": {"en": "Home"}}`
+
+// captureStdout runs a command, returning its exit code and everything it printed to stdout
+func captureStdout(t *testing.T, command func() int) (int, string) {
+	t.Helper()
+	reader, writer, pipeErr := os.Pipe()
+
+	if pipeErr != nil {
+		t.Fatal(pipeErr)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+
+	go func() { // Read while the command runs, so it doesn't block on a full pipe
+		content, _ := io.ReadAll(reader)
+		output <- string(content)
+	}()
+
+	exitCode := command()
+	os.Stdout = stdout
+	writer.Close()
+	return exitCode, <-output
+}
+
+// TestTermsCommands ensures each terms command prints or changes the Terms, saving the config only if it succeeds
+func TestTermsCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		want   int
+		output string
+		terms  string // Terms of the saved config, or empty if the config must be unchanged
+	}{
+		{
+			name:   "list",
+			args:   []string{"terms", "list"},
+			output: "apples\ten\t{\"one\":\"{count} apple\",\"other\":\"{count} apples\"}\nempty\nhello\ten\tHello\nhello\tfi\tHei\nnav.home\ten\tHome\n",
+		},
+		{name: "list language", args: []string{"terms", "list", "--lang", "FI"}, output: "hello\tfi\tHei\n"},
+		{name: "list prefix", args: []string{"terms", "list", "--prefix", "nav."}, output: "nav.home\ten\tHome\n"},
+		{name: "list JSON", args: []string{"terms", "list", "--json", "--prefix", "nav."}, output: "{\n\t\"nav.home\": {\n\t\t\"en\": \"Home\"\n\t}\n}\n"},
+		{name: "list arguments", args: []string{"terms", "list", "hello"}, want: ExitUsage},
+		{name: "list invalid language", args: []string{"terms", "list", "--lang", "!!"}, want: ExitUsage},
+		{name: "get", args: []string{"terms", "get", "hello"}, output: "en\tHello\nfi\tHei\n"},
+		{name: "get language", args: []string{"terms", "get", "--lang", "fi", "hello"}, output: "Hei\n"},
+		{name: "get plural", args: []string{"terms", "get", "--lang", "en", "apples"}, output: "{count} apples\n"},
+		{name: "get JSON", args: []string{"terms", "get", "--json", "apples"}, output: "{\n\t\"en\": {\n\t\t\"one\": \"{count} apple\",\n\t\t\"other\": \"{count} apples\"\n\t}\n}\n"},
+		{name: "get untranslated", args: []string{"terms", "get", "--lang", "fi", "nav.home"}, want: ExitFailure},
+		{name: "get missing", args: []string{"terms", "get", "missing"}, want: ExitFailure},
+		{name: "get without a Term", args: []string{"terms", "get"}, want: ExitUsage},
+		{
+			name:   "set",
+			args:   []string{"terms", "set", "hello", "Hi"},
+			output: "Set hello in en\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "empty": {}, "hello": {"en": "Hi", "fi": "Hei"}, "nav.home": {"en": "Home"}}`,
+		},
+		{
+			name:   "set new",
+			args:   []string{"terms", "set", "--lang", "fi_FI", "bye", "Näkemiin"},
+			output: "Set bye in fi-FI\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "bye": {"fi-FI": "Näkemiin"}, "empty": {}, "hello": {"en": "Hello", "fi": "Hei"}, "nav.home": {"en": "Home"}}`,
+		},
+		{
+			name:   "set plural",
+			args:   []string{"terms", "set", "--plural", "--lang", "fi", "apples", "one={count} omena", "other={count} omenaa"},
+			output: "Set the plural forms of apples in fi\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}, "fi": {"one": "{count} omena", "other": "{count} omenaa"}}, "empty": {}, "hello": {"en": "Hello", "fi": "Hei"}, "nav.home": {"en": "Home"}}`,
+		},
+		{name: "set invalid value", args: []string{"terms", "set", "hello", "{name"}, want: ExitFailure},
+		{name: "set invalid form", args: []string{"terms", "set", "--plural", "apples", "one={count", "other=#"}, want: ExitFailure},
+		{name: "set unknown category", args: []string{"terms", "set", "--plural", "apples", "lots=#", "other=#"}, want: ExitFailure},
+		{name: "set without other", args: []string{"terms", "set", "--plural", "apples", "one=#"}, want: ExitFailure},
+		{name: "set malformed form", args: []string{"terms", "set", "--plural", "apples", "#"}, want: ExitUsage},
+		{name: "set without a value", args: []string{"terms", "set", "hello"}, want: ExitUsage},
+		{
+			name:   "delete",
+			args:   []string{"terms", "delete", "hello", "empty"},
+			output: "Deleted hello, empty\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "nav.home": {"en": "Home"}}`,
+		},
+		{
+			name:   "delete language",
+			args:   []string{"terms", "delete", "--lang", "fi", "hello"},
+			output: "Deleted hello in fi\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "empty": {}, "hello": {"en": "Hello"}, "nav.home": {"en": "Home"}}`,
+		},
+		{name: "delete missing", args: []string{"terms", "delete", "hello", "missing"}, want: ExitFailure}, // No Term is deleted
+		{name: "delete untranslated", args: []string{"terms", "delete", "--lang", "fi", "hello", "nav.home"}, want: ExitFailure},
+		{name: "delete without a Term", args: []string{"terms", "delete"}, want: ExitUsage},
+		{
+			name:   "rename",
+			args:   []string{"terms", "rename", "hello", "greeting"},
+			output: "Renamed hello to greeting\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "empty": {}, "greeting": {"en": "Hello", "fi": "Hei"}, "nav.home": {"en": "Home"}}`,
+		},
+		{name: "rename to existing", args: []string{"terms", "rename", "hello", "nav.home"}, want: ExitFailure},
+		{name: "rename missing", args: []string{"terms", "rename", "missing", "greeting"}, want: ExitFailure},
+		{name: "rename without a new name", args: []string{"terms", "rename", "hello"}, want: ExitUsage},
+		{
+			name:   "copy",
+			args:   []string{"terms", "copy", "apples", "pears"},
+			output: "Copied apples to pears\n",
+			terms:  `{"apples": {"en": {"one": "{count} apple", "other": "{count} apples"}}, "empty": {}, "hello": {"en": "Hello", "fi": "Hei"}, "nav.home": {"en": "Home"}, "pears": {"en": {"one": "{count} apple", "other": "{count} apples"}}}`,
+		},
+		{name: "copy to existing", args: []string{"terms", "copy", "hello", "apples"}, want: ExitFailure},
+		{name: "copy missing", args: []string{"terms", "copy", "missing", "pears"}, want: ExitFailure},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": ` + testTerms + `}`
+			dir := useTestConfig(t, config, map[string]string{})
+			args := append(test.args, "--config", ConfigFile) // Each command sets the ConfigFile from its flag
+			exitCode, output := captureStdout(t, func() int { return Run(args) })
+
+			if exitCode != test.want || output != test.output {
+				t.Errorf("Run(%q) = %d %q, want %d %q", args, exitCode, output, test.want, test.output)
+			}
+
+			saved, readErr := os.ReadFile(filepath.Join(dir, "frala.json"))
+
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+
+			if test.terms == "" { // If the command must not change the config
+				if string(saved) != config {
+					t.Errorf("Config = %s, want it unchanged", saved)
+				}
+
+				return
+			}
+
+			var got frala.ConfigOptions
+			var want map[string]frala.Term
+
+			if decodeErr := json.Unmarshal(saved, &got); decodeErr != nil {
+				t.Fatalf("Saved config can't be read back: %v", decodeErr)
+			}
+
+			if decodeErr := json.Unmarshal([]byte(test.terms), &want); decodeErr != nil {
+				t.Fatal(decodeErr)
+			}
+
+			if !reflect.DeepEqual(got.Terms, want) {
+				t.Errorf("Saved Terms = %v, want %v", got.Terms, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// This file contains the watch mode of the Frala Tool, parsing files again when their templates, Fragments or Terms change
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/JoshStrobl/frala"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WatchInterval is how often watched files are checked for changes
+const WatchInterval = 500 * time.Millisecond
+
+// watchedOutput is a file parsed in a language, with the files and Terms its last parse depended on
+type watchedOutput struct {
+	File         string             // File being parsed
+	Language     string             // Language the file is parsed in
+	Dependencies frala.Dependencies // Dependencies of the last parse
+}
+
+// fileState is the state of a watched file, compared between checks to detect changes
+type fileState struct {
+	Exists  bool      // Whether the file exists
+	ModTime time.Time // Modification time of the file
+	Size    int64     // Size of the file
+}
+
+// Watcher parses files whenever their templates, Fragments or the config file change, only parsing the files and languages affected
+type Watcher struct {
+	ConfigFile string                // ConfigFile is the config file reloaded when it changes
+	Output     string                // Output is the directory parsed files are written to
+	outputs    []*watchedOutput      // Outputs, one per file and language
+	states     map[string]fileState  // States of every watched file, keyed by absolute path
+	terms      map[string]frala.Term // Terms of the last build, to find which Terms changed
+	settings   string                // Config of the last build without its Terms, to find whether anything else changed
+}
+
+// Watch parses the files provided in the languages provided to the output directory, then parses them again as they change until the Frala Tool is stopped
+func Watch(files, languages []string, output string) {
+	watcher := &Watcher{ConfigFile: ConfigFile, Output: output, states: make(map[string]fileState)}
+
+	for _, file := range files { // For each file, in each language
+		if !strings.HasSuffix(file, ".html") { // Only HTML files are parsed
+			continue
+		}
+
+		for _, language := range languages {
+			watcher.outputs = append(watcher.outputs, &watchedOutput{File: file, Language: language})
+		}
+	}
+
+	watcher.snapshotConfig()
+	watcher.track(watcher.ConfigFile)
+	watcher.build(watcher.outputs)
+
+	fmt.Println("Watching for changes. Press Ctrl+C to stop.")
+
+	for {
+		time.Sleep(WatchInterval)
+		watcher.Check()
+	}
+}
+
+// Check parses any outputs affected by changes since the last Check
+func (w *Watcher) Check() {
+	changedFiles := w.changedFiles()
+
+	if len(changedFiles) == 0 { // If nothing changed
+		return
+	}
+
+	var affected []*watchedOutput
+	configFile, _ := filepath.Abs(w.ConfigFile)
+
+	if changedFiles[configFile] { // If the config changed, reload it and find which Terms changed
+		fmt.Println("Changed: " + w.ConfigFile)
+		delete(changedFiles, configFile)
+
+		if reloadErr := Engine.ReloadConfig(); reloadErr != nil { // If the config is now invalid, keep using the previous Config until it is fixed
+			fmt.Println(reloadErr)
+		} else {
+			oldTerms, oldSettings := w.terms, w.settings
+			w.snapshotConfig()
+
+			if w.settings != oldSettings { // If anything other than Terms changed, such as Fallbacks or Languages, every output may be affected
+				affected = w.outputs
+			} else {
+				affected = w.affectedByTerms(changedTerms(oldTerms, w.terms))
+			}
+		}
+	}
+
+	var changedPaths []string
+
+	for file := range changedFiles {
+		changedPaths = append(changedPaths, file)
+	}
+
+	sort.Strings(changedPaths)
+
+	for _, file := range changedPaths {
+		fmt.Println("Changed: " + file)
+	}
+
+	for _, output := range w.outputs { // For each output not already affected, add it if it depends on a changed file
+		if !containsOutput(affected, output) {
+			for _, dependency := range output.Dependencies.Files { // For each file the output depends on
+				if changedFiles[dependency] {
+					affected = append(affected, output)
+					break
+				}
+			}
+		}
+	}
+
+	if len(affected) != 0 {
+		fmt.Printf("Parsing %d of %d outputs\n", len(affected), len(w.outputs))
+		w.build(affected)
+	}
+}
+
+// build parses each output, writing its content and tracking its new Dependencies
+func (w *Watcher) build(outputs []*watchedOutput) {
+	Engine.ClearFallbacks() // Only report the Fallbacks of this build
+
+	for _, output := range outputs { // For each output
+		parseResponse := Engine.ParseWith(output.File, frala.RenderOptions{Language: output.Language})
+		output.Dependencies = parseResponse.Dependencies
+		WriteParseResponse(parseResponse, OutputFile(w.Output, parseResponse.Name))
+
+		for _, dependency := range output.Dependencies.Files { // Watch any new Fragments
+			w.track(dependency)
+		}
+	}
+
+	PrintFallbacks()
+}
+
+// track starts watching a file, if it isn't already watched
+func (w *Watcher) track(file string) {
+	absFile, absErr := filepath.Abs(file)
+
+	if absErr != nil {
+		return
+	}
+
+	if _, watched := w.states[absFile]; !watched {
+		w.states[absFile] = statFile(absFile)
+	}
+}
+
+// changedFiles gets every watched file which changed, was created or was removed since the last check
+func (w *Watcher) changedFiles() map[string]bool {
+	changed := make(map[string]bool)
+
+	for file, previous := range w.states { // For each watched file
+		if current := statFile(file); current != previous {
+			w.states[file] = current
+			changed[file] = true
+		}
+	}
+
+	return changed
+}
+
+// statFile gets the state of a file, which doesn't exist if it can't be read
+func statFile(file string) fileState {
+	fileInfo, statErr := os.Stat(file)
+
+	if statErr != nil {
+		return fileState{}
+	}
+
+	return fileState{Exists: true, ModTime: fileInfo.ModTime(), Size: fileInfo.Size()}
+}
+
+// snapshotConfig copies the Terms and other settings of the Config, so the next reload can be compared against them
+func (w *Watcher) snapshotConfig() {
+	w.terms = make(map[string]frala.Term)
+
+	for name, term := range Engine.Config.Terms { // Copy each Term, since its values may be changed in place
+		w.terms[name] = make(frala.Term)
+
+		for language, value := range term {
+			w.terms[name][language] = value
+		}
+	}
+
+	settings := *Engine.Config
+	settings.Terms = nil
+	settingsContent, _ := json.Marshal(settings)
+	w.settings = string(settingsContent)
+}
+
+// changedTerms gets the languages of each Term whose value was added, changed or removed
+func changedTerms(oldTerms, newTerms map[string]frala.Term) map[string][]string {
+	changed := make(map[string][]string)
+
+	compare := func(name string, languages frala.Term) {
+		for language := range languages { // For each language of the Term
+			oldValue, oldExists := oldTerms[name][language]
+			newValue, newExists := newTerms[name][language]
+
+			if (oldExists != newExists || !reflect.DeepEqual(oldValue, newValue)) && !containsString(changed[name], language) {
+				changed[name] = append(changed[name], language)
+			}
+		}
+	}
+
+	for name, term := range oldTerms {
+		compare(name, term)
+	}
+
+	for name, term := range newTerms {
+		compare(name, term)
+	}
+
+	return changed
+}
+
+// affectedByTerms gets every output which looked up a changed Term in a language whose fallback chain includes a changed language
+func (w *Watcher) affectedByTerms(changed map[string][]string) []*watchedOutput {
+	var affected []*watchedOutput
+
+	for _, output := range w.outputs { // For each output
+		if outputUsesTerms(output, changed) {
+			affected = append(affected, output)
+		}
+	}
+
+	return affected
+}
+
+// outputUsesTerms returns whether the last parse of an output would have used any of the changed Terms
+func outputUsesTerms(output *watchedOutput, changed map[string][]string) bool {
+	for term, lookupLanguages := range output.Dependencies.Terms { // For each Term the output used
+		changedLanguages, isChanged := changed[term]
+
+		if !isChanged {
+			continue
+		}
+
+		for _, lookupLanguage := range lookupLanguages { // For each language the Term was looked up in
+			for _, chainLanguage := range Engine.FallbackChain(lookupLanguage) {
+				if containsString(changedLanguages, chainLanguage) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// containsOutput returns whether the outputs contain the output
+func containsOutput(outputs []*watchedOutput, output *watchedOutput) bool {
+	for _, o := range outputs {
+		if o == output {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsString returns whether the strings contain the value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
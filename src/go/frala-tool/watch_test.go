@@ -0,0 +1,102 @@
+// This file contains the tests of the watch mode, parsing only the files affected by a change
+
+package main
+
+import (
+	"github.com/JoshStrobl/frala"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestChangedTerms ensures each language of a Term whose value was added, changed or removed is reported
+func TestChangedTerms(t *testing.T) {
+	oldTerms := map[string]frala.Term{
+		"hello":   {"en": frala.Value{Text: "Hello"}, "fi": frala.Value{Text: "Hei"}},
+		"bye":     {"en": frala.Value{Text: "Bye"}},
+		"removed": {"en": frala.Value{Text: "Removed"}},
+	}
+
+	newTerms := map[string]frala.Term{
+		"hello": {"en": frala.Value{Text: "Hi"}, "fi": frala.Value{Text: "Hei"}},
+		"bye":   {"en": frala.Value{Text: "Bye"}, "fi": frala.Value{Text: "Näkemiin"}},
+		"added": {"de": frala.Value{Text: "Neu"}},
+	}
+
+	want := map[string][]string{
+		"hello":   {"en"},
+		"bye":     {"fi"},
+		"removed": {"en"},
+		"added":   {"de"},
+	}
+
+	if got := changedTerms(oldTerms, newTerms); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedTerms = %v, want %v", got, want)
+	}
+
+	if got := changedTerms(newTerms, newTerms); len(got) != 0 {
+		t.Errorf("changedTerms of the same Terms = %v, want none", got)
+	}
+}
+
+// TestWatcherCheck ensures only the outputs depending on a changed template, Fragment or Term in their fallback chain are parsed again
+func TestWatcherCheck(t *testing.T) {
+	config := `{"DefaultLanguage": "en", "Languages": ["en"], "Terms": {"hello": {"en": "Hello"}, "bye": {"en": "Bye"}}}`
+
+	tests := []struct {
+		name    string
+		changes map[string]string
+		want    []string // Outputs parsed again
+	}{
+		{"nothing", map[string]string{}, nil},
+		{"template", map[string]string{"about.html": `{{ type="term" src="bye" }}!`}, []string{"about.html"}},
+		{"fragment", map[string]string{"header.html": `{{ type="term" src="hello" }}!`}, []string{"index.html"}},
+		{"term", map[string]string{"frala.json": `{"DefaultLanguage": "en", "Languages": ["en"], "Terms": {"hello": {"en": "Hello"}, "bye": {"en": "Goodbye"}}}`}, []string{"about.html"}},
+		{"term outside the fallback chain", map[string]string{"frala.json": `{"DefaultLanguage": "en", "Languages": ["en"], "Terms": {"hello": {"en": "Hello", "fi": "Hei"}, "bye": {"en": "Bye"}}}`}, nil},
+		{"settings", map[string]string{"frala.json": `{"DefaultLanguage": "en", "Languages": ["en", "fi"], "Terms": {"hello": {"en": "Hello"}, "bye": {"en": "Bye"}}}`}, []string{"about.html", "index.html"}},
+		{"invalid config", map[string]string{"frala.json": `{"DefaultLanguage": `}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := useTestConfig(t, config, map[string]string{
+				"header.html": `{{ type="term" src="hello" }}`,
+				"index.html":  `{{ type="fragment" src="header.html" }}`,
+				"about.html":  `{{ type="term" src="bye" }}`,
+			})
+
+			output := filepath.Join(dir, "out")
+			watcher := &Watcher{ConfigFile: ConfigFile, Output: output, states: make(map[string]fileState)}
+
+			for _, file := range []string{"index.html", "about.html"} {
+				watcher.outputs = append(watcher.outputs, &watchedOutput{File: filepath.Join(dir, file), Language: "en"})
+			}
+
+			watcher.snapshotConfig()
+			watcher.track(watcher.ConfigFile)
+			watcher.build(watcher.outputs)
+
+			if removeErr := os.RemoveAll(output); removeErr != nil { // Only the outputs parsed again are written
+				t.Fatal(removeErr)
+			}
+
+			writeTestFiles(t, dir, test.changes) // Each change has a different size, so it is detected without waiting for the modification time to change
+			watcher.Check()
+
+			var got []string
+			entries, _ := os.ReadDir(output)
+
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parsed %v again, want %v", got, test.want)
+			}
+		})
+	}
+}
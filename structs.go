@@ -4,27 +4,50 @@ package frala
 
 // ConfigOptions is the configuration for Frala
 type ConfigOptions struct {
-	CurrentLanguage string          // Current language we're parsing with. Defaults to DefaultLanguage
-	DefaultLanguage string          // Default Language string, if not declared, default to en
-	Direction       string          // Direction string, informs what the likely direction of the DefaultLanguage is
-	Languages       []string        // Languages is a list of languages (string)
-	Terms           map[string]Term // Terms is a map of strings (term names) to individual Terms
+	CurrentLanguage string              // Current language we're parsing with when no language is provided. Defaults to DefaultLanguage
+	DefaultLanguage string              // Default Language string, if not declared, default to en
+	Direction       string              // Direction string, informs what the likely direction of the DefaultLanguage is
+	Directions      map[string]string   // Directions overrides the direction (ltr or rtl) of languages, keyed by language
+	Fallbacks       map[string][]string // Fallbacks is a map of languages to the languages to use, in order, when a Term is not translated into them
+	Languages       []string            // Languages is a list of languages (string)
+	Strict          bool                // Strict is whether any Diagnostic fails a parse, rather than only read and syntax errors
+	Terms           map[string]Term     // Terms is a map of strings (term names) to individual Terms
 }
 
-// Term is a map[string]string, as each Term has a map of language -> value (where language is a string and value is a string)
-type Term map[string]string
+// Term is a map[string]Value, as each Term has a map of language -> value (where language is a string)
+type Term map[string]Value
+
+// Value is the value of a Term for a language. It is either a single string, or a string per CLDR plural category.
+// In frala.json, a Value is a string or an object of plural categories, such as { "one" : "1 file", "other" : "many files" }
+type Value struct {
+	Text   string            // Text of the value, if it has no plural forms
+	Plural map[string]string // Plural forms of the value, keyed by CLDR plural category (zero, one, two, few, many, other)
+}
 
 // Context is a struct that has properties relating to the type and type's associated information.
-// Used by the line parser.
+// Created from a TagNode by NewContext.
 type Context struct {
-	Lang   string // Language of the term (if not a fragment)
-	Source string // Source such as the word or link to fragment
-	Type   string // Type of the Context (fragment or term)
+	Lang      string            // Language of the term (if not a fragment)
+	Source    string            // Source such as the word or link to fragment
+	Type      string            // Type of the Context (fragment or term)
+	Count     string            // Count used to choose the plural form of a term
+	Arguments map[string]string // Arguments are any other attributes, filling {name} placeholders of a term
+	File      string            // File the Frala syntax was declared in
+	Node      Node              // Node the Context was created from
 }
 
 // ParseResponse is a struct that contains both the content of a file and associated parsing error
 type ParseResponse struct {
-	Name    string // Name of the file
-	Content string // Content of the parsed file
-	Error   error  // Error that occurred during parsing
+	Name         string       // Name of the file
+	Language     string       // Language the file was parsed in
+	Content      string       // Content of the parsed file
+	Error        error        // Error that failed the parse, such as a read or syntax error
+	Diagnostics  []ParseError // Diagnostics are problems found while parsing, including those of any Fragments
+	Dependencies Dependencies // Dependencies are the files and Terms used while parsing
+}
+
+// Dependencies are the files and Terms a parse used, so it can be parsed again when any of them change
+type Dependencies struct {
+	Files []string            // Files read or attempted to be read: the template and each Fragment, as absolute paths or paths in the FS of the Engine
+	Terms map[string][]string // Terms used, other than built-in Terms, with the languages each was looked up in
 }
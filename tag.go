@@ -0,0 +1,250 @@
+// This file contains functionality for parsing and canonicalizing BCP 47 language tags and POSIX locale identifiers
+
+package frala
+
+import (
+	"strings"
+)
+
+// Tag is a parsed BCP 47 language tag, such as zh-Hant-TW or ca-ES-valencia
+type Tag struct {
+	Language   string   // Language subtag, lowercase, such as zh
+	Script     string   // Script subtag, title case, such as Hant
+	Region     string   // Region subtag, uppercase, such as TW or 419
+	Variants   []string // Variant subtags, lowercase, such as valencia
+	Extensions []string // Extensions, lowercase, each with its singleton, such as u-ca-buddhist
+	PrivateUse string   // Private use subtags, lowercase, with their x singleton, such as x-frala
+}
+
+// deprecatedLanguages are deprecated language subtags and their replacements, so tags such as iw and he are the same language
+var deprecatedLanguages = map[string]string{
+	"in": "id", // Indonesian
+	"iw": "he", // Hebrew
+	"ji": "yi", // Yiddish
+	"jw": "jv", // Javanese
+	"mo": "ro", // Moldavian, now Romanian
+}
+
+// posixModifiers are POSIX locale modifiers which are BCP 47 scripts, such as sr_RS@latin
+var posixModifiers = map[string]string{
+	"cyrillic":   "Cyrl",
+	"devanagari": "Deva",
+	"latin":      "Latn",
+}
+
+// ParseTag parses a BCP 47 language tag or POSIX locale identifier, such as en-GB, en_GB, EN-gb, zh-Hant-TW or sr_RS.UTF-8@latin
+// Subtags may be separated by - or _. The charset of a POSIX locale is ignored, and its modifier becomes a script or variant.
+func ParseTag(tag string) (Tag, error) {
+	var t Tag
+	value := strings.TrimSpace(tag)
+	modifier := ""
+
+	if index := strings.IndexByte(value, '@'); index != -1 { // If this is a POSIX locale with a modifier, such as sr_RS@latin
+		value, modifier = value[:index], strings.ToLower(value[index+1:])
+	}
+
+	if index := strings.IndexByte(value, '.'); index != -1 { // If this is a POSIX locale with a charset, such as en_US.UTF-8
+		value = value[:index]
+	}
+
+	if value == "" {
+		return t, &TagError{Tag: tag, Message: "Expected a language"}
+	}
+
+	subtags := strings.Split(strings.ToLower(strings.Replace(value, "_", "-", -1)), "-")
+
+	for _, subtag := range subtags { // Ensure there are no empty subtags, such as in en--GB
+		if subtag == "" {
+			return t, &TagError{Tag: tag, Message: "Expected a subtag"}
+		}
+	}
+
+	index := 0
+
+	if subtags[0] == "x" { // If the whole tag is private use, such as x-klingon
+		t.PrivateUse = strings.Join(subtags, "-")
+		return t, checkSubtags(tag, subtags[1:], 1, 8)
+	}
+
+	if !isAlpha(subtags[0]) || len(subtags[0]) < 2 || len(subtags[0]) > 8 {
+		return t, &TagError{Tag: tag, Message: subtags[0] + " is not a language"}
+	}
+
+	t.Language = subtags[0]
+	index++
+
+	if len(t.Language) <= 3 && index < len(subtags) && len(subtags[index]) == 3 && isAlpha(subtags[index]) { // An extended language subtag, such as zh-yue, is used as the language
+		t.Language = subtags[index]
+		index++
+	}
+
+	if replacement, deprecated := deprecatedLanguages[t.Language]; deprecated {
+		t.Language = replacement
+	}
+
+	if index < len(subtags) && len(subtags[index]) == 4 && isAlpha(subtags[index]) { // Script, such as Hant
+		t.Script = strings.ToUpper(subtags[index][:1]) + subtags[index][1:]
+		index++
+	}
+
+	if index < len(subtags) && ((len(subtags[index]) == 2 && isAlpha(subtags[index])) || (len(subtags[index]) == 3 && isDigits(subtags[index]))) { // Region, such as TW or 419
+		t.Region = strings.ToUpper(subtags[index])
+		index++
+	}
+
+	for index < len(subtags) && isVariant(subtags[index]) { // Variants, such as valencia or 1996
+		t.Variants = append(t.Variants, subtags[index])
+		index++
+	}
+
+	for index < len(subtags) && len(subtags[index]) == 1 && subtags[index] != "x" { // Extensions, such as u-ca-buddhist
+		start := index
+		index++
+
+		for index < len(subtags) && len(subtags[index]) >= 2 && len(subtags[index]) <= 8 && isAlphanumeric(subtags[index]) {
+			index++
+		}
+
+		if index == start+1 {
+			return t, &TagError{Tag: tag, Message: "Extension " + subtags[start] + " has no subtags"}
+		}
+
+		t.Extensions = append(t.Extensions, strings.Join(subtags[start:index], "-"))
+	}
+
+	if index < len(subtags) && subtags[index] == "x" { // Private use, such as x-frala
+		if privateErr := checkSubtags(tag, subtags[index+1:], 1, 8); privateErr != nil {
+			return t, privateErr
+		}
+
+		t.PrivateUse = strings.Join(subtags[index:], "-")
+		index = len(subtags)
+	}
+
+	if index < len(subtags) {
+		return t, &TagError{Tag: tag, Message: "Unexpected subtag " + subtags[index]}
+	}
+
+	if script, isScript := posixModifiers[modifier]; isScript && t.Script == "" {
+		t.Script = script
+	} else if modifier != "" && modifier != "euro" && isVariant(modifier) { // Modifiers such as valencia are variants. @euro only selects a currency
+		t.Variants = append(t.Variants, modifier)
+	}
+
+	return t, nil
+}
+
+// checkSubtags ensures each subtag is alphanumeric with a length within the range, and that there is at least one
+func checkSubtags(tag string, subtags []string, minLength, maxLength int) error {
+	if len(subtags) == 0 {
+		return &TagError{Tag: tag, Message: "Expected a subtag after x"}
+	}
+
+	for _, subtag := range subtags {
+		if len(subtag) < minLength || len(subtag) > maxLength || !isAlphanumeric(subtag) {
+			return &TagError{Tag: tag, Message: subtag + " is not a valid subtag"}
+		}
+	}
+
+	return nil
+}
+
+// isVariant returns whether the subtag is a variant: 5 to 8 letters or digits, or 4 starting with a digit
+func isVariant(subtag string) bool {
+	if !isAlphanumeric(subtag) {
+		return false
+	}
+
+	return (len(subtag) >= 5 && len(subtag) <= 8) || (len(subtag) == 4 && subtag[0] >= '0' && subtag[0] <= '9')
+}
+
+// isAlpha returns whether the subtag is only ASCII letters
+func isAlpha(subtag string) bool {
+	for _, c := range subtag {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+
+	return subtag != ""
+}
+
+// isAlphanumeric returns whether the subtag is only ASCII letters and digits
+func isAlphanumeric(subtag string) bool {
+	for _, c := range subtag {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return subtag != ""
+}
+
+// String returns the canonical BCP 47 form of the Tag, such as zh-Hant-TW
+func (t Tag) String() string {
+	subtags := []string{}
+
+	for _, subtag := range []string{t.Language, t.Script, t.Region} {
+		if subtag != "" {
+			subtags = append(subtags, subtag)
+		}
+	}
+
+	subtags = append(subtags, t.Variants...)
+	subtags = append(subtags, t.Extensions...)
+
+	if t.PrivateUse != "" {
+		subtags = append(subtags, t.PrivateUse)
+	}
+
+	return strings.Join(subtags, "-")
+}
+
+// POSIX returns the Tag as a POSIX locale identifier, as used by the Language header of PO files, such as pt_BR or sr_RS@latin
+// Only the language, region and script or first variant are kept.
+func (t Tag) POSIX() string {
+	locale := t.Language
+
+	if t.Region != "" {
+		locale += "_" + t.Region
+	}
+
+	for modifier, script := range posixModifiers { // If the script has a POSIX modifier
+		if script == t.Script {
+			return locale + "@" + modifier
+		}
+	}
+
+	if len(t.Variants) != 0 {
+		locale += "@" + t.Variants[0]
+	}
+
+	return locale
+}
+
+// Parent returns the Tag without its most specific subtag, such as zh-Hant for zh-Hant-TW and zh for zh-Hant
+// Variants, extensions and private use are removed first. The parent of a language alone is an empty Tag.
+func (t Tag) Parent() Tag {
+	switch {
+	case len(t.Variants) != 0 || len(t.Extensions) != 0 || t.PrivateUse != "":
+		return Tag{Language: t.Language, Script: t.Script, Region: t.Region}
+	case t.Region != "":
+		return Tag{Language: t.Language, Script: t.Script}
+	case t.Script != "":
+		return Tag{Language: t.Language}
+	}
+
+	return Tag{}
+}
+
+// CanonicalLanguage canonicalizes a BCP 47 language tag or POSIX locale identifier, so en-GB, en_GB and EN-gb are all en-GB
+// A language which is not a valid tag is returned trimmed but otherwise as-is.
+func CanonicalLanguage(language string) string {
+	tag, tagErr := ParseTag(language)
+
+	if tagErr != nil {
+		return strings.TrimSpace(language)
+	}
+
+	return tag.String()
+}
@@ -0,0 +1,75 @@
+// This file contains the tests of parsing and canonicalizing language tags
+
+package frala
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseTag ensures BCP 47 tags and POSIX locales are canonicalized, and converted back into POSIX locales and parents
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		posix  string
+		parent string
+	}{
+		{"en", "en", "en", ""},
+		{"EN-gb", "en-GB", "en_GB", "en"},
+		{"en_GB", "en-GB", "en_GB", "en"},
+		{"pt_BR.UTF-8", "pt-BR", "pt_BR", "pt"},
+		{" fi ", "fi", "fi", ""},
+		{"zh-hant-tw", "zh-Hant-TW", "zh_TW", "zh-Hant"},
+		{"zh-Hant", "zh-Hant", "zh", "zh"},
+		{"es-419", "es-419", "es_419", "es"},
+		{"ca-ES-valencia", "ca-ES-valencia", "ca_ES@valencia", "ca-ES"},
+		{"ca_ES.UTF-8@valencia", "ca-ES-valencia", "ca_ES@valencia", "ca-ES"},
+		{"sr_RS@latin", "sr-Latn-RS", "sr_RS@latin", "sr-Latn"},
+		{"de_DE@euro", "de-DE", "de_DE", "de"},
+		{"de-1996", "de-1996", "de@1996", "de"},
+		{"iw", "he", "he", ""},
+		{"in-ID", "id-ID", "id_ID", "id"},
+		{"zh-yue-HK", "yue-HK", "yue_HK", "yue"},
+		{"th-TH-u-nu-thai", "th-TH-u-nu-thai", "th_TH", "th-TH"},
+		{"en-US-x-frala", "en-US-x-frala", "en_US", "en-US"},
+		{"x-klingon", "x-klingon", "", ""},
+	}
+
+	for _, test := range tests {
+		tag, tagErr := ParseTag(test.tag)
+
+		if tagErr != nil {
+			t.Errorf("ParseTag(%q) = %v", test.tag, tagErr)
+			continue
+		}
+
+		if got := tag.String(); got != test.want {
+			t.Errorf("ParseTag(%q) = %s, want %s", test.tag, got, test.want)
+		}
+
+		if posix := tag.POSIX(); posix != test.posix {
+			t.Errorf("POSIX(%q) = %s, want %s", test.tag, posix, test.posix)
+		}
+
+		if parent := tag.Parent().String(); parent != test.parent {
+			t.Errorf("Parent(%q) = %s, want %s", test.tag, parent, test.parent)
+		}
+	}
+}
+
+// TestParseTagInvalid ensures malformed tags are a TagError, and CanonicalLanguage keeps them as-is
+func TestParseTagInvalid(t *testing.T) {
+	for _, tag := range []string{"", " ", "e", "en-GB-toolongvariant", "en--GB", "en-", "12", "en-GB-u", "en-x", "en-x-toolongsubtag", "en-GB-!", "fr-u-ca-x"} {
+		_, tagErr := ParseTag(tag)
+		var invalid *TagError
+
+		if !errors.As(tagErr, &invalid) {
+			t.Errorf("ParseTag(%q) = %v, want a TagError", tag, tagErr)
+		}
+	}
+
+	if canonical := CanonicalLanguage(" not a tag "); canonical != "not a tag" {
+		t.Errorf("CanonicalLanguage = %q, want it trimmed", canonical)
+	}
+}
@@ -2,54 +2,384 @@
 
 package frala
 
-// GetValue gets the value of a language from a Term, if it exists
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetValue gets the value of a language from a Term of the default Engine, falling back to other languages if it isn't translated
 func GetValue(termName, language string) string {
+	return Default().GetValue(termName, language)
+}
+
+// SetTerm enables you to set a Term to Terms of the default Engine
+func SetTerm(termName string) {
+	Default().SetTerm(termName)
+}
+
+// SetValue enables you to set the value of a Term language of the default Engine
+func SetValue(termName, language, value string) {
+	Default().SetValue(termName, language, value)
+}
+
+// SetPluralValue enables you to set the plural forms of a Term language of the default Engine
+func SetPluralValue(termName, language string, forms map[string]string) error {
+	return Default().SetPluralValue(termName, language, forms)
+}
+
+// DeleteTerm deletes a Term from Terms of the default Engine
+func DeleteTerm(termName string) {
+	Default().DeleteTerm(termName)
+}
+
+// DeleteValue deletes a language / value from a Term of the default Engine
+func DeleteValue(termName, language string) {
+	Default().DeleteValue(termName, language)
+}
+
+// RenameTerm renames a Term of the default Engine, keeping its values
+func RenameTerm(termName, newTermName string) error {
+	return Default().RenameTerm(termName, newTermName)
+}
+
+// CopyTerm copies a Term of the default Engine, along with its values, to a new Term
+func CopyTerm(termName, newTermName string) error {
+	return Default().CopyTerm(termName, newTermName)
+}
+
+// GetValue gets the value of a language from a Term, using the first language of its FallbackChain the Term is translated into
+// Returns an empty string if the Term is not translated into the language or any of its fallbacks.
+func (e *Engine) GetValue(termName, language string) string {
 	if language != "" { // If a language is defined
-		language = Sanitize(language) // Ensure it is sanitized
+		language = CanonicalLanguage(language) // Ensure it is canonical
 	} else { // If a language is not defined
-		language = Config.DefaultLanguage // Set to Default Language
+		language = e.config().DefaultLanguage // Set to Default Language
 	}
 
-	SetTerm(termName)                                 // Automatically set the term if it doesn't exist already
-	value, exists := Config.Terms[termName][language] // Get the language value of this term in Terms
+	value, used, _ := e.lookupFallback(termName, language) // Get the value of this term in the language, or its fallbacks
 
-	if !exists { // If the language key/val does not exist
-		value = "Term " + termName + " is not translated into " + language
+	if message, messageErr := ParseMessage(value.String()); messageErr == nil { // If the value is valid MessageFormat, format it so quoted text is unescaped. Arguments without a value are left as-is
+		formatted, _, _ := message.Format(used, nil)
+		return formatted
 	}
 
-	return value
+	return value.String()
+}
+
+// FormatValue formats the value of a language from a Term of the default Engine as MessageFormat, with the arguments provided
+func FormatValue(termName, language string, arguments map[string]string) (string, error) {
+	return Default().FormatValue(termName, language, arguments)
+}
+
+// FormatValue formats the value of a language from a Term as MessageFormat with the arguments provided, using the first language of its FallbackChain the Term is translated into
+// Returns an error if the Term is not translated, its value is invalid, or an argument is missing or has an invalid value.
+func (e *Engine) FormatValue(termName, language string, arguments map[string]string) (string, error) {
+	if language == "" { // If a language is not defined
+		language = e.config().DefaultLanguage
+	}
+
+	language = CanonicalLanguage(language)
+	value, used, exists := e.lookupFallback(termName, language)
+
+	if !exists { // If the Term is not translated into this language or any of its fallbacks
+		return "", errors.New("Term " + termName + " is not translated into " + language + " or any of its fallbacks")
+	}
+
+	message, messageErr := ParseMessage(value.String())
+
+	if messageErr != nil { // If the value isn't valid MessageFormat syntax
+		return "", fmt.Errorf("Term %s has invalid syntax in %s: %w", termName, used, messageErr)
+	}
+
+	formatted, missing, formatErr := message.Format(used, arguments)
+
+	if formatErr == nil && len(missing) != 0 { // If an argument is missing
+		formatErr = errors.New("No value for argument " + missing[0] + " of Term " + termName)
+	}
+
+	return formatted, formatErr
+}
+
+// CheckTerms checks that every value of every Term of the default Engine is valid MessageFormat syntax
+func CheckTerms() error {
+	return Default().CheckTerms()
+}
+
+// CheckTerms checks that every value of every Term is valid MessageFormat syntax, returning an error describing each invalid value
+func (e *Engine) CheckTerms() error {
+	var problems []string
+
+	e.termsLock.RLock()
+
+	for termName, term := range e.Config.Terms { // For each Term
+		for language, value := range term { // For each language of the Term
+			for _, form := range value.forms() {
+				if _, messageErr := ParseMessage(form); messageErr != nil {
+					problems = append(problems, "Term "+termName+" has invalid syntax in "+language+": "+messageErr.Error())
+				}
+			}
+		}
+	}
+
+	e.termsLock.RUnlock()
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems) // Sort the problems, so they are reported in a consistent order
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// lookupValue gets the value of a canonical language from a Term and whether it exists, automatically setting the Term if it doesn't exist already
+func (e *Engine) lookupValue(termName, language string) (Value, bool) {
+	e.termsLock.RLock()
+	term, termExists := e.Config.Terms[termName]
+	value, exists := term[language]
+	e.termsLock.RUnlock()
+
+	if !termExists { // If the Term doesn't exist, set it so it can be translated later
+		e.SetTerm(termName)
+	}
+
+	return value, exists
 }
 
 // SetTerm enables you to set a Term to Terms
-func SetTerm(termName string) {
+func (e *Engine) SetTerm(termName string) {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	e.setTerm(termName)
+}
+
+// setTerm sets a Term to Terms if it doesn't exist already, without locking the Terms
+func (e *Engine) setTerm(termName string) {
 	if termName != "" { // If the termName passed isn't empty
-		if _, exists := Config.Terms[termName]; !exists { // If the Term doesn't exist already
-			Config.Terms[termName] = Term{} // Set this termName to be equivelant to a new Term
+		if _, exists := e.Config.Terms[termName]; !exists { // If the Term doesn't exist already
+			e.Config.Terms[termName] = Term{} // Set this termName to be equivelant to a new Term
 		}
 	}
 }
 
 // SetValue enables you to set the value of a Term language
-func SetValue(termName, language, value string) {
-	SetTerm(termName)                 // Automatically set the term if it doesn't exist already
-	term, _ := Config.Terms[termName] // Get the term if it exists
-	language = Sanitize(language)     // Ensure the language is sanitized
+func (e *Engine) SetValue(termName, language, value string) {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	e.setTerm(termName)                    // Automatically set the term if it doesn't exist already
+	term, _ := e.Config.Terms[termName]    // Get the term if it exists
+	language = CanonicalLanguage(language) // Ensure the language is canonical
 
-	term[language] = value        // Set the value of a particular language to this term
-	Config.Terms[termName] = term // Update the Terms
+	term[language] = Value{Text: value} // Set the value of a particular language to this term
+	e.Config.Terms[termName] = term     // Update the Terms
+}
+
+// SetPluralValue enables you to set the plural forms of a Term language, keyed by CLDR plural category
+// Returns an error, leaving the Term unchanged, if a form isn't keyed by a CLDR plural category or there is no other form.
+func (e *Engine) SetPluralValue(termName, language string, forms map[string]string) error {
+	if formsErr := validatePluralForms(forms); formsErr != nil {
+		return formsErr
+	}
+
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	e.setTerm(termName)                    // Automatically set the term if it doesn't exist already
+	term, _ := e.Config.Terms[termName]    // Get the term if it exists
+	language = CanonicalLanguage(language) // Ensure the language is canonical
+	plural := make(map[string]string)
+
+	for category, form := range forms { // Copy the forms, so they can't be changed without the lock
+		plural[category] = form
+	}
+
+	term[language] = Value{Plural: plural} // Set the plural forms of a particular language to this term
+	e.Config.Terms[termName] = term        // Update the Terms
+	return nil
 }
 
 // DeleteTerm deletes a Term from Terms
-func DeleteTerm(termName string) {
-	delete(Config.Terms, termName) // Simply call builtin delete
+func (e *Engine) DeleteTerm(termName string) {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	delete(e.Config.Terms, termName) // Simply call builtin delete
+}
+
+// RenameTerm renames a Term, keeping its values
+// Returns an error if the Term doesn't exist, or a Term with the new name already exists.
+func (e *Engine) RenameTerm(termName, newTermName string) error {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	term, copyErr := e.copyTerm(termName, newTermName)
+
+	if copyErr != nil {
+		return copyErr
+	}
+
+	e.Config.Terms[newTermName] = term
+	delete(e.Config.Terms, termName)
+	return nil
+}
+
+// CopyTerm copies a Term, along with its values, to a new Term
+// Returns an error if the Term doesn't exist, or a Term with the new name already exists.
+func (e *Engine) CopyTerm(termName, newTermName string) error {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	term, copyErr := e.copyTerm(termName, newTermName)
+
+	if copyErr != nil {
+		return copyErr
+	}
+
+	e.Config.Terms[newTermName] = term
+	return nil
+}
+
+// copyTerm copies the values of a Term, ensuring it can be copied to the new name, without locking the Terms
+func (e *Engine) copyTerm(termName, newTermName string) (Term, error) {
+	term, exists := e.Config.Terms[termName]
+
+	if !exists {
+		return nil, errors.New("Term " + termName + " does not exist")
+	} else if newTermName == "" {
+		return nil, errors.New("The new name of Term " + termName + " is empty")
+	} else if _, newExists := e.Config.Terms[newTermName]; newExists {
+		return nil, errors.New("Term " + newTermName + " already exists")
+	}
+
+	copied := make(Term)
+
+	for language, value := range term { // Copy each value, including its plural forms, so the Terms don't share them
+		copiedValue := Value{Text: value.Text}
+
+		if value.Plural != nil {
+			copiedValue.Plural = make(map[string]string)
+
+			for category, form := range value.Plural {
+				copiedValue.Plural[category] = form
+			}
+		}
+
+		copied[language] = copiedValue
+	}
+
+	return copied, nil
 }
 
 // DeleteValue deletes a language / value from a Term
-func DeleteValue(termName, language string) {
-	term, exists := Config.Terms[termName] // Get the term if it exists
+func (e *Engine) DeleteValue(termName, language string) {
+	e.termsLock.Lock()
+	defer e.termsLock.Unlock()
+
+	term, exists := e.Config.Terms[termName] // Get the term if it exists
 
 	if exists { // If the term exists
-		delete(term, language)        // Delete from the term the language key/val
-		Config.Terms[termName] = term // Update the Terms
+		delete(term, CanonicalLanguage(language)) // Delete from the term the language key/val
+		e.Config.Terms[termName] = term           // Update the Terms
+	}
+}
+
+// String gets the Text of the Value, or its PluralOther form if it has plural forms
+func (v Value) String() string {
+	if v.IsPlural() {
+		return v.Plural[PluralOther]
+	}
+
+	return v.Text
+}
+
+// forms gets the Text of the Value, or each of its plural forms
+func (v Value) forms() []string {
+	if !v.IsPlural() {
+		return []string{v.Text}
+	}
+
+	forms := make([]string, 0, len(v.Plural))
+
+	for _, form := range v.Plural {
+		forms = append(forms, form)
+	}
+
+	return forms
+}
+
+// IsPlural returns whether the Value has plural forms
+func (v Value) IsPlural() bool {
+	return len(v.Plural) != 0
+}
+
+// Form gets the form of the Value for a count in a language, using the plural rules of the language
+// Falls back to the PluralOther form if the Value has no form for the category. Values without plural forms return their Text.
+func (v Value) Form(language, count string) (string, error) {
+	if !v.IsPlural() { // If there is only one form
+		return v.Text, nil
+	}
+
+	category, categoryErr := PluralCategory(language, count)
+
+	if form, exists := v.Plural[category]; exists && categoryErr == nil {
+		return form, nil
+	}
+
+	if categoryErr == nil { // If the count is valid, but has no form of its own
+		categoryErr = errors.New("No " + category + " plural form for count " + count + ", using " + PluralOther)
+	}
+
+	return v.Plural[PluralOther], categoryErr
+}
+
+// MarshalJSON encodes the Value as a string, or an object of plural forms if it has any
+func (v Value) MarshalJSON() ([]byte, error) {
+	if v.IsPlural() {
+		return json.Marshal(v.Plural)
 	}
+
+	return json.Marshal(v.Text)
+}
+
+// UnmarshalJSON decodes the Value from a string, or an object of plural forms keyed by CLDR plural category
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var plural map[string]string
+
+	if textErr := json.Unmarshal(data, &v.Text); textErr == nil { // If the Value is a string
+		v.Plural = nil
+		return nil
+	}
+
+	if pluralErr := json.Unmarshal(data, &plural); pluralErr != nil {
+		return errors.New("Value must be a string or an object of plural forms: " + pluralErr.Error())
+	}
+
+	if formsErr := validatePluralForms(plural); formsErr != nil {
+		return formsErr
+	}
+
+	v.Text = ""
+	v.Plural = plural
+	return nil
+}
+
+// validatePluralForms ensures each plural form is keyed by a CLDR plural category, and that there is an other form
+func validatePluralForms(forms map[string]string) error {
+	for category := range forms { // For each plural form, ensure it is a CLDR plural category
+		switch category {
+		case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		default:
+			return errors.New(category + " is not a plural category. Use zero, one, two, few, many or other")
+		}
+	}
+
+	if _, exists := forms[PluralOther]; !exists { // Every language uses other, so it must always be provided
+		return errors.New("Plural forms must include other")
+	}
+
+	return nil
 }
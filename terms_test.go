@@ -0,0 +1,125 @@
+// This file contains the tests of setting and getting Terms
+
+package frala
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestSetPluralValue ensures only plural forms the Config can be read back with are set
+func TestSetPluralValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		forms map[string]string
+		valid bool
+	}{
+		{"one and other", map[string]string{"one": "# file", "other": "# files"}, true},
+		{"every category", map[string]string{"zero": "a", "one": "b", "two": "c", "few": "d", "many": "e", "other": "f"}, true},
+		{"unknown category", map[string]string{"foo": "y", "other": "# files"}, false},
+		{"uppercase category", map[string]string{"One": "# file", "other": "# files"}, false},
+		{"no other", map[string]string{"one": "# file"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"files": {"en": "Files"}}}`, nil)
+			setErr := engine.SetPluralValue("files", "en", test.forms)
+
+			if test.valid != (setErr == nil) {
+				t.Fatalf("SetPluralValue(%v) error = %v, want valid %v", test.forms, setErr, test.valid)
+			}
+
+			if !test.valid { // The Term must be unchanged
+				if value := engine.Config.Terms["files"]["en"]; value.IsPlural() {
+					t.Errorf("invalid forms were set: %v", value.Plural)
+				}
+
+				return
+			}
+
+			content, marshalErr := json.Marshal(engine.Config.Terms)
+
+			if marshalErr != nil {
+				t.Fatal(marshalErr)
+			}
+
+			var terms map[string]Term
+
+			if unmarshalErr := json.Unmarshal(content, &terms); unmarshalErr != nil { // The saved Terms must still be readable
+				t.Errorf("Terms can't be read back: %v", unmarshalErr)
+			}
+		})
+	}
+}
+
+// TestRenameAndCopyTerm ensures Terms are renamed or copied with all their values, and are left unchanged if the Term doesn't exist or the new name is taken
+func TestRenameAndCopyTerm(t *testing.T) {
+	tests := []struct {
+		name    string
+		copy    bool
+		term    string
+		newTerm string
+		valid   bool
+		want    []string // Terms after the rename or copy
+	}{
+		{"rename", false, "files", "documents", true, []string{"bye", "documents"}},
+		{"copy", true, "files", "documents", true, []string{"bye", "documents", "files"}},
+		{"rename missing", false, "missing", "documents", false, []string{"bye", "files"}},
+		{"copy missing", true, "missing", "documents", false, []string{"bye", "files"}},
+		{"rename to existing", false, "files", "bye", false, []string{"bye", "files"}},
+		{"copy to existing", true, "files", "bye", false, []string{"bye", "files"}},
+		{"rename to itself", false, "files", "files", false, []string{"bye", "files"}},
+		{"rename to empty", false, "files", "", false, []string{"bye", "files"}},
+		{"copy to empty", true, "files", "", false, []string{"bye", "files"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine, _ := newTestEngine(t, `{"DefaultLanguage": "en", "Terms": {"files": {"en": {"one": "# file", "other": "# files"}, "fi": "Tiedostot"}, "bye": {"en": "Bye"}}}`, nil)
+			move := engine.RenameTerm
+
+			if test.copy {
+				move = engine.CopyTerm
+			}
+
+			if moveErr := move(test.term, test.newTerm); (moveErr == nil) != test.valid {
+				t.Fatalf("%s(%q, %q) error = %v, want valid %v", test.name, test.term, test.newTerm, moveErr, test.valid)
+			}
+
+			var got []string
+
+			for termName := range engine.Config.Terms {
+				got = append(got, termName)
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Terms = %v, want %v", got, test.want)
+			}
+
+			if got := engine.GetValue("bye", "en"); got != "Bye" {
+				t.Errorf("bye = %q, want it unchanged", got)
+			}
+
+			if !test.valid {
+				return
+			}
+
+			moved := engine.Config.Terms[test.newTerm]
+
+			if moved["fi"].Text != "Tiedostot" || moved["en"].Plural["one"] != "# file" {
+				t.Errorf("%s = %v, want every value of %s", test.newTerm, moved, test.term)
+			}
+
+			moved["en"].Plural["one"] = "# document" // The copy must not share plural forms with the original
+
+			if original, kept := engine.Config.Terms[test.term]; kept && original["en"].Plural["one"] != "# file" {
+				t.Errorf("Changing the copy changed %s to %v", test.term, original["en"].Plural)
+			}
+		})
+	}
+}
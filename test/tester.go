@@ -13,6 +13,10 @@ func main() {
 
 	if parsedResponse.Error == nil { // If there was no parse error, because we're awesome
 		fmt.Println(parsedResponse.Content) // Output parsedContent
+
+		for _, diagnostic := range parsedResponse.Diagnostics { // For each Diagnostic, such as the intentional import of a non-existant file
+			fmt.Println(diagnostic.Error())
+		}
 	} else { // If we failed to parse
 		fmt.Println("You fool, you doomed us all! Okay, not really, but here is the error message: ", parsedResponse.Error)
 	}